@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/google/uuid"
+)
+
+// attachChirpDetails fills in what isn't stored on the chirps row.
+func (cfg *apiConfig) attachChirpDetails(ctx context.Context, chirps []Chirp) error {
+	err := cfg.attachEntities(ctx, chirps)
+	if err != nil {
+		return err
+	}
+	err = cfg.attachAttachments(ctx, chirps)
+	if err != nil {
+		return err
+	}
+	return cfg.attachViewerState(ctx, chirps)
+}
+
+func (cfg *apiConfig) attachViewerState(ctx context.Context, chirps []Chirp) error {
+	user, ok := userFromContext(ctx)
+	if !ok || len(chirps) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(chirps))
+	for i := range chirps {
+		ids[i] = chirps[i].ID
+	}
+
+	liked, err := cfg.db.ListLikedChirpIDs(ctx, database.ListLikedChirpIDsParams{
+		UserID:   user.ID,
+		ChirpIds: ids,
+	})
+	if err != nil {
+		return err
+	}
+
+	rechirped, err := cfg.db.ListRechirpedChirpIDs(ctx, database.ListRechirpedChirpIDsParams{
+		UserID:   user.ID,
+		ChirpIds: ids,
+	})
+	if err != nil {
+		return err
+	}
+
+	likedSet := make(map[uuid.UUID]bool, len(liked))
+	for _, id := range liked {
+		likedSet[id] = true
+	}
+	rechirpedSet := make(map[uuid.UUID]bool, len(rechirped))
+	for _, id := range rechirped {
+		rechirpedSet[id] = true
+	}
+
+	for i := range chirps {
+		likedByMe := likedSet[chirps[i].ID]
+		rechirpedByMe := rechirpedSet[chirps[i].ID]
+		chirps[i].LikedByMe = &likedByMe
+		chirps[i].RechirpedByMe = &rechirpedByMe
+	}
+
+	return nil
+}
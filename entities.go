@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/RafaelTauschek/http-server/internal/entities"
+	"github.com/google/uuid"
+)
+
+// Indices are counted in Unicode code points.
+type Entities struct {
+	Hashtags []Hashtag `json:"hashtags"`
+	Mentions []Mention `json:"mentions"`
+}
+
+type Hashtag struct {
+	Tag     string `json:"tag"`
+	Indices [2]int `json:"indices"`
+}
+
+type Mention struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Handle  string    `json:"handle"`
+	Indices [2]int    `json:"indices"`
+}
+
+// saveChirpEntities drops mentions of handles nobody uses.
+func saveChirpEntities(ctx context.Context, q *database.Queries, chirpID uuid.UUID, body string) error {
+	err := q.DeleteChirpEntities(ctx, chirpID)
+	if err != nil {
+		return err
+	}
+
+	found := entities.Extract(body)
+
+	var handles []string
+	for _, e := range found {
+		if e.Kind == entities.KindMention {
+			handles = append(handles, e.Text)
+		}
+	}
+
+	users := map[string]uuid.UUID{}
+	if len(handles) > 0 {
+		data, err := q.GetUsersByHandles(ctx, handles)
+		if err != nil {
+			return err
+		}
+		for _, user := range data {
+			users[entities.Normalize(user.Handle.String)] = user.ID
+		}
+	}
+
+	for _, e := range found {
+		switch e.Kind {
+		case entities.KindHashtag:
+			err = q.CreateChirpHashtag(ctx, database.CreateChirpHashtagParams{
+				ChirpID:     chirpID,
+				Tag:         e.Text,
+				StartOffset: int32(e.Start),
+				EndOffset:   int32(e.End),
+			})
+		case entities.KindMention:
+			userID, ok := users[e.Text]
+			if !ok {
+				continue
+			}
+			err = q.CreateChirpMention(ctx, database.CreateChirpMentionParams{
+				ChirpID:     chirpID,
+				UserID:      userID,
+				StartOffset: int32(e.Start),
+				EndOffset:   int32(e.End),
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cfg *apiConfig) attachEntities(ctx context.Context, chirps []Chirp) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+
+	byID := make(map[uuid.UUID]*Entities, len(chirps))
+	ids := make([]uuid.UUID, len(chirps))
+	for i := range chirps {
+		chirps[i].Entities = &Entities{Hashtags: []Hashtag{}, Mentions: []Mention{}}
+		byID[chirps[i].ID] = chirps[i].Entities
+		ids[i] = chirps[i].ID
+	}
+
+	hashtags, err := cfg.db.ListChirpHashtags(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, h := range hashtags {
+		e := byID[h.ChirpID]
+		e.Hashtags = append(e.Hashtags, Hashtag{
+			Tag:     h.Tag,
+			Indices: [2]int{int(h.StartOffset), int(h.EndOffset)},
+		})
+	}
+
+	mentions, err := cfg.db.ListChirpMentions(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, m := range mentions {
+		e := byID[m.ChirpID]
+		e.Mentions = append(e.Mentions, Mention{
+			UserID:  m.UserID,
+			Handle:  m.Handle.String,
+			Indices: [2]int{int(m.StartOffset), int(m.EndOffset)},
+		})
+	}
+
+	return nil
+}
@@ -11,3 +11,5 @@ require github.com/joho/godotenv v1.5.1
 require golang.org/x/crypto v0.29.0
 
 require github.com/golang-jwt/jwt/v5 v5.2.1
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/RafaelTauschek/http-server/internal/blob"
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/RafaelTauschek/http-server/internal/media"
+	"github.com/google/uuid"
+)
+
+const maxAttachmentsPerChirp = 4
+
+// multipartOverhead allows for boundaries and part headers around the file.
+const multipartOverhead = 64 << 10
+
+var errTooManyAttachments = errors.New("too many attachments")
+
+type Attachment struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	URL         string    `json:"url"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	Thumbnail   Thumbnail `json:"thumbnail"`
+}
+
+type Thumbnail struct {
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
+
+func newAttachment(a database.Attachment) Attachment {
+	url := "/api/attachments/" + a.ID.String()
+	return Attachment{
+		ID:          a.ID,
+		CreatedAt:   a.CreatedAt,
+		URL:         url,
+		ContentType: a.ContentType,
+		SizeBytes:   a.SizeBytes,
+		Width:       int(a.Width),
+		Height:      int(a.Height),
+		Thumbnail: Thumbnail{
+			URL:         url + "/thumbnail",
+			ContentType: a.ThumbnailContentType,
+			Width:       int(a.ThumbnailWidth),
+			Height:      int(a.ThumbnailHeight),
+		},
+	}
+}
+
+func (cfg *apiConfig) handlerUploadAttachment(w http.ResponseWriter, r *http.Request) {
+	user := mustUser(r.Context())
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp id", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirpById(r.Context(), chirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "No chirp found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp", err)
+		return
+	}
+	if chirp.UserID != user.ID {
+		respondWithError(w, http.StatusForbidden, "Only the author can add attachments", nil)
+		return
+	}
+
+	count, err := cfg.db.CountChirpAttachments(r.Context(), chirp.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't count attachments", err)
+		return
+	}
+	if count >= maxAttachmentsPerChirp {
+		respondWithError(w, http.StatusConflict, "A chirp can have at most 4 attachments", nil)
+		return
+	}
+
+	userPlan, err := cfg.planFor(r.Context(), user)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load plan", err)
+		return
+	}
+
+	limit := userPlan.maxUploadBytes(cfg.maxUploadBytes)
+	r.Body = http.MaxBytesReader(w, r.Body, limit+multipartOverhead)
+	data, err := readUpload(r, limit)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		respondWithError(w, http.StatusRequestEntityTooLarge, "File is too large", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't read file", err)
+		return
+	}
+
+	img, err := media.Process(data)
+	if errors.Is(err, media.ErrUnsupportedType) {
+		respondWithError(w, http.StatusUnsupportedMediaType, "File must be a PNG, JPEG or GIF image", err)
+		return
+	}
+	if errors.Is(err, media.ErrTooManyPixels) {
+		respondWithError(w, http.StatusRequestEntityTooLarge, "Image dimensions are too large", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't process image", err)
+		return
+	}
+
+	id := uuid.New()
+	blobKey := "attachments/" + id.String()
+	thumbnailKey := blobKey + "-thumbnail"
+
+	err = cfg.blobs.Put(r.Context(), blobKey, bytes.NewReader(data))
+	if err == nil {
+		err = cfg.blobs.Put(r.Context(), thumbnailKey, bytes.NewReader(img.Thumbnail))
+	}
+	if err != nil {
+		cfg.deleteBlobs(r.Context(), blobKey, thumbnailKey)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't store file", err)
+		return
+	}
+
+	// Count again with the chirp locked so concurrent uploads can't both
+	// take the last slot.
+	var attachment database.Attachment
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		_, err := q.GetChirpForUpdate(r.Context(), chirp.ID)
+		if err != nil {
+			return err
+		}
+
+		count, err := q.CountChirpAttachments(r.Context(), chirp.ID)
+		if err != nil {
+			return err
+		}
+		if count >= maxAttachmentsPerChirp {
+			return errTooManyAttachments
+		}
+
+		attachment, err = q.CreateAttachment(r.Context(), database.CreateAttachmentParams{
+			ID:                   id,
+			ChirpID:              chirp.ID,
+			ContentType:          img.ContentType,
+			SizeBytes:            int64(len(data)),
+			Width:                int32(img.Width),
+			Height:               int32(img.Height),
+			BlobKey:              blobKey,
+			ThumbnailContentType: img.ThumbnailContentType,
+			ThumbnailWidth:       int32(img.ThumbnailWidth),
+			ThumbnailHeight:      int32(img.ThumbnailHeight),
+			ThumbnailKey:         thumbnailKey,
+		})
+		return err
+	})
+	if err != nil {
+		cfg.deleteBlobs(r.Context(), blobKey, thumbnailKey)
+	}
+	if errors.Is(err, errTooManyAttachments) {
+		respondWithError(w, http.StatusConflict, "A chirp can have at most 4 attachments", err)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "No chirp found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save attachment", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, newAttachment(attachment))
+}
+
+func readUpload(r *http.Request, limit int64) ([]byte, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, errors.New("no file field in form")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(part, limit+1))
+		part.Close()
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(data)) > limit {
+			return nil, &http.MaxBytesError{Limit: limit}
+		}
+		return data, nil
+	}
+}
+
+func (cfg *apiConfig) handlerGetAttachment(w http.ResponseWriter, r *http.Request) {
+	attachment, ok := cfg.visibleAttachment(w, r)
+	if !ok {
+		return
+	}
+	cfg.serveBlob(w, r, attachment.BlobKey, attachment.ContentType)
+}
+
+func (cfg *apiConfig) handlerGetAttachmentThumbnail(w http.ResponseWriter, r *http.Request) {
+	attachment, ok := cfg.visibleAttachment(w, r)
+	if !ok {
+		return
+	}
+	cfg.serveBlob(w, r, attachment.ThumbnailKey, attachment.ThumbnailContentType)
+}
+
+func (cfg *apiConfig) handlerDeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	user := mustUser(r.Context())
+
+	attachment, ok := cfg.visibleAttachment(w, r)
+	if !ok {
+		return
+	}
+
+	chirp, err := cfg.db.GetChirpById(r.Context(), attachment.ChirpID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp", err)
+		return
+	}
+	if chirp.UserID != user.ID {
+		respondWithError(w, http.StatusForbidden, "Only the author can remove attachments", nil)
+		return
+	}
+
+	err = cfg.db.DeleteAttachment(r.Context(), attachment.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete attachment", err)
+		return
+	}
+	cfg.deleteBlobs(r.Context(), attachment.BlobKey, attachment.ThumbnailKey)
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// visibleAttachment treats attachments of chirps the user can't see as
+// missing.
+func (cfg *apiConfig) visibleAttachment(w http.ResponseWriter, r *http.Request) (database.Attachment, bool) {
+	attachmentID, err := uuid.Parse(r.PathValue("attachmentID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid attachment id", err)
+		return database.Attachment{}, false
+	}
+
+	attachment, err := cfg.db.GetAttachment(r.Context(), attachmentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "No attachment found", err)
+		return database.Attachment{}, false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve attachment", err)
+		return database.Attachment{}, false
+	}
+
+	chirp, err := cfg.db.GetChirpById(r.Context(), attachment.ChirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "No attachment found", err)
+		return database.Attachment{}, false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp", err)
+		return database.Attachment{}, false
+	}
+
+	if !chirpVisible(r.Context(), chirp) {
+		respondWithError(w, http.StatusNotFound, "No attachment found", nil)
+		return database.Attachment{}, false
+	}
+
+	return attachment, true
+}
+
+func (cfg *apiConfig) serveBlob(w http.ResponseWriter, r *http.Request, key, contentType string) {
+	rc, err := cfg.blobs.Get(r.Context(), key)
+	if errors.Is(err, blob.ErrNotFound) {
+		respondWithError(w, http.StatusNotFound, "No attachment found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't read attachment", err)
+		return
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, rc)
+}
+
+// deleteBlobs only logs failures, which leave orphaned files behind.
+func (cfg *apiConfig) deleteBlobs(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		err := cfg.blobs.Delete(ctx, key)
+		if err != nil {
+			slog.Error("Couldn't delete blob", "key", key, "error", err)
+		}
+	}
+}
+
+func (cfg *apiConfig) attachAttachments(ctx context.Context, chirps []Chirp) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+
+	byID := make(map[uuid.UUID]*Chirp, len(chirps))
+	ids := make([]uuid.UUID, len(chirps))
+	for i := range chirps {
+		byID[chirps[i].ID] = &chirps[i]
+		ids[i] = chirps[i].ID
+	}
+
+	data, err := cfg.db.ListChirpAttachments(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, a := range data {
+		chirp := byID[a.ChirpID]
+		chirp.Attachments = append(chirp.Attachments, newAttachment(a))
+	}
+
+	return nil
+}
@@ -6,66 +6,138 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/RafaelTauschek/http-server/internal/auth"
 	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/RafaelTauschek/http-server/internal/moderation"
 	"github.com/google/uuid"
 )
 
 type Chirp struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string    `json:"body"`
-	UserId    uuid.UUID `json:"user_id"`
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Body      string     `json:"body"`
+	UserId    uuid.UUID  `json:"user_id"`
+	InReplyTo *uuid.UUID `json:"in_reply_to,omitempty"`
+
+	LikeCount    int `json:"like_count"`
+	RechirpCount int `json:"rechirp_count"`
+	// LikedByMe and RechirpedByMe are only set for authenticated requests.
+	LikedByMe     *bool `json:"liked_by_me,omitempty"`
+	RechirpedByMe *bool `json:"rechirped_by_me,omitempty"`
+
+	Entities    *Entities         `json:"entities,omitempty"`
+	Attachments []Attachment      `json:"attachments,omitempty"`
+	Moderation  *ModerationReport `json:"moderation,omitempty"`
 }
 
 func (cfg *apiConfig) handlerAddChirps(w http.ResponseWriter, r *http.Request) {
 	type parameters struct {
-		Body string `json:"body"`
+		Body      string     `json:"body"`
+		InReplyTo *uuid.UUID `json:"in_reply_to"`
+	}
+
+	user := mustUser(r.Context())
+	userPlan, err := cfg.planFor(r.Context(), user)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load plan", err)
+		return
 	}
 
 	decoder := json.NewDecoder(r.Body)
 	params := parameters{}
 
-	err := decoder.Decode(&params)
+	err = decoder.Decode(&params)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
 		return
 	}
 
-	token, err := auth.GetBearerToken(r.Header)
-	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Couldn't get bearer token", err)
+	result, ok := cfg.checkChirpBody(w, userPlan, params.Body)
+	if !ok {
 		return
 	}
 
-	userID, err := auth.ValidateJWT(token, cfg.secret)
-	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+	var parentID uuid.NullUUID
+	if params.InReplyTo != nil {
+		parent, err := cfg.db.GetChirpById(r.Context(), *params.InReplyTo)
+		if err != nil || !chirpVisible(r.Context(), parent) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find the chirp to reply to", err)
+			return
+		}
+		parentID = uuid.NullUUID{UUID: parent.ID, Valid: true}
 	}
 
-	if len(params.Body) > 140 {
-		respondWithError(w, http.StatusBadRequest, "Chirp is to long", nil)
+	var chrip database.Chirp
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		var err error
+		chrip, err = q.CreateChirp(context.Background(), database.CreateChirpParams{
+			Body:     result.Text,
+			UserID:   user.ID,
+			ParentID: parentID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = saveChirpEntities(r.Context(), q, chrip.ID, chrip.Body)
+		if err != nil {
+			return err
+		}
+		return reportFlaggedChirp(r.Context(), q, chrip.ID, result)
+	})
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't  create chirp", err)
 		return
 	}
 
-	val := profaneFilter(params.Body)
+	created := []Chirp{{
+		ID:           chrip.ID,
+		CreatedAt:    chrip.CreatedAt,
+		UpdatedAt:    chrip.UpdatedAt,
+		Body:         chrip.Body,
+		UserId:       chrip.UserID,
+		LikeCount:    int(chrip.LikeCount),
+		RechirpCount: int(chrip.RechirpCount),
+		InReplyTo:    inReplyTo(chrip.ParentID),
 
-	chrip, err := cfg.db.CreateChirp(context.Background(), database.CreateChirpParams{
-		Body:   val,
-		UserID: userID,
-	})
+		Moderation: newModerationReport(result),
+	}}
 
+	err = cfg.attachChirpDetails(r.Context(), created)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't  create chirp", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp details", err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, Chirp{
-		ID:        chrip.ID,
-		CreatedAt: chrip.CreatedAt,
-		UpdatedAt: chrip.UpdatedAt,
-		Body:      chrip.Body,
-		UserId:    chrip.UserID,
-	})
+	respondWithJSON(w, http.StatusCreated, created[0])
+}
+
+func (cfg *apiConfig) checkChirpBody(w http.ResponseWriter, authorPlan plan, body string) (moderation.Result, bool) {
+	if len(body) > authorPlan.MaxChirpLength {
+		respondWithError(w, http.StatusBadRequest, "Chirp is to long", nil)
+		return moderation.Result{}, false
+	}
+
+	result := cfg.moderate(body)
+	if result.Rejected {
+		type rejection struct {
+			Error      string            `json:"error"`
+			Moderation *ModerationReport `json:"moderation"`
+		}
+		respondWithJSON(w, http.StatusBadRequest, rejection{
+			Error:      "Chirp contains prohibited content",
+			Moderation: newModerationReport(result),
+		})
+		return moderation.Result{}, false
+	}
+
+	return result, true
+}
+
+func inReplyTo(parentID uuid.NullUUID) *uuid.UUID {
+	if !parentID.Valid {
+		return nil
+	}
+	return &parentID.UUID
 }
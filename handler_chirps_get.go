@@ -2,66 +2,222 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
-	"sort"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/RafaelTauschek/http-server/internal/entities"
 	"github.com/google/uuid"
 )
 
-func (cfg *apiConfig) getSortedChirps(ctx context.Context, id, sortDirection string) ([]database.Chirp, error) {
-	var data []database.Chirp
-	var err error
+type ChirpsPage struct {
+	Chirps     []Chirp `json:"chirps"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+type chirpFilter struct {
+	AuthorIDs     []uuid.UUID
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	Hashtag       sql.NullString
+	Mention       sql.NullString
+	// MentionedUserID comes from the path, not the query.
+	MentionedUserID uuid.NullUUID
+	// TimelineOf limits the listing to a user and the people they follow.
+	TimelineOf    uuid.NullUUID
+	SortBy        string
+	SortDirection string
+	After         *cursor
+	Limit         int
+}
+
+var (
+	chirpSortFields = []string{"created_at", "updated_at"}
+)
 
-	if id != "" {
-		data, err = cfg.db.GetChirpsByUser(ctx, uuid.MustParse(id))
-	} else {
-		data, err = cfg.db.GetChrips(ctx)
+// parseChirpFilter reports every problem at once.
+func parseChirpFilter(query url.Values) (chirpFilter, error) {
+	filter := chirpFilter{
+		SortBy:        "created_at",
+		SortDirection: "asc",
 	}
+	var problems []string
 
+	for _, param := range query["author_id"] {
+		for _, id := range strings.Split(param, ",") {
+			parsed, err := uuid.Parse(strings.TrimSpace(id))
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("author_id: %q is not a valid id", id))
+				continue
+			}
+			filter.AuthorIDs = append(filter.AuthorIDs, parsed)
+		}
+	}
+
+	bounds := []struct {
+		key string
+		dst *sql.NullTime
+	}{
+		{"created_after", &filter.CreatedAfter},
+		{"created_before", &filter.CreatedBefore},
+	}
+	for _, b := range bounds {
+		val := query.Get(b.key)
+		if val == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %q is not an RFC 3339 timestamp", b.key, val))
+			continue
+		}
+		// created_at has no time zone and is stored in UTC.
+		*b.dst = sql.NullTime{Time: parsed.UTC(), Valid: true}
+	}
+
+	if query.Has("hashtag") {
+		tag := strings.TrimPrefix(query.Get("hashtag"), "#")
+		if entities.ValidHashtag(tag) {
+			filter.Hashtag = sql.NullString{String: entities.Normalize(tag), Valid: true}
+		} else {
+			problems = append(problems, "hashtag: must be up to 50 letters, digits or underscores, including a letter")
+		}
+	}
+
+	if query.Has("mention") {
+		handle := strings.TrimPrefix(query.Get("mention"), "@")
+		if entities.ValidHandle(handle) {
+			filter.Mention = sql.NullString{String: handle, Valid: true}
+		} else {
+			problems = append(problems, "mention: must be 3 to 30 letters, digits or underscores")
+		}
+	}
+
+	if sortBy := query.Get("sort_by"); sortBy != "" {
+		if !slices.Contains(chirpSortFields, sortBy) {
+			problems = append(problems, fmt.Sprintf("sort_by: must be one of %s", strings.Join(chirpSortFields, ", ")))
+		}
+		filter.SortBy = sortBy
+	}
+
+	if sort := query.Get("sort"); sort != "" {
+		if sort != "asc" && sort != "desc" {
+			problems = append(problems, "sort: must be asc or desc")
+		}
+		filter.SortDirection = sort
+	}
+
+	limit, err := parseLimit(query.Get("limit"))
 	if err != nil {
-		return nil, err
+		problems = append(problems, "limit: "+err.Error())
 	}
+	filter.Limit = limit
 
-	sortFunc := func(i, j int) bool {
-		if sortDirection == "desc" {
-			return data[i].CreatedAt.After(data[j].CreatedAt)
+	if param := query.Get("cursor"); param != "" {
+		c, err := decodeCursor(param)
+		if err != nil {
+			problems = append(problems, "cursor: "+err.Error())
 		}
-		return data[i].CreatedAt.Before(data[j].CreatedAt)
+		filter.After = &c
 	}
 
-	sort.Slice(data, sortFunc)
-	return data, nil
+	if len(problems) > 0 {
+		return chirpFilter{}, errors.New(strings.Join(problems, "; "))
+	}
+
+	return filter, nil
 }
 
-func (cfg *apiConfig) handlerGetChirps(w http.ResponseWriter, r *http.Request) {
-	id := r.URL.Query().Get("author_id")
-	sort := r.URL.Query().Get("sort")
-	sortDirection := "asc"
+func (cfg *apiConfig) listChirps(ctx context.Context, filter chirpFilter) ([]database.Chirp, error) {
+	var cursorTime sql.NullTime
+	var cursorID uuid.NullUUID
+
+	if filter.After != nil {
+		cursorTime = sql.NullTime{Time: filter.After.Time, Valid: true}
+		cursorID = uuid.NullUUID{UUID: filter.After.ID, Valid: true}
+	}
+
+	params := database.ListChirpsByCreatedAscParams{
+		AuthorIds:       filter.AuthorIDs,
+		CreatedAfter:    filter.CreatedAfter,
+		CreatedBefore:   filter.CreatedBefore,
+		Hashtag:         filter.Hashtag,
+		Mention:         filter.Mention,
+		MentionedUserID: filter.MentionedUserID,
+		TimelineOf:      filter.TimelineOf,
+		CursorTime:      cursorTime,
+		CursorID:        cursorID,
+		Limit:           int32(filter.Limit + 1),
+	}
+
+	switch {
+	case filter.SortBy == "updated_at" && filter.SortDirection == "desc":
+		return cfg.db.ListChirpsByUpdatedDesc(ctx, database.ListChirpsByUpdatedDescParams(params))
+	case filter.SortBy == "updated_at":
+		return cfg.db.ListChirpsByUpdatedAsc(ctx, database.ListChirpsByUpdatedAscParams(params))
+	case filter.SortDirection == "desc":
+		return cfg.db.ListChirpsByCreatedDesc(ctx, database.ListChirpsByCreatedDescParams(params))
+	default:
+		return cfg.db.ListChirpsByCreatedAsc(ctx, params)
+	}
+}
 
-	if sort == "desc" {
-		sortDirection = "desc"
+func (cfg *apiConfig) handlerGetChirps(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseChirpFilter(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid parameters: "+err.Error(), err)
+		return
 	}
 
-	data, err := cfg.getSortedChirps(context.Background(), id, sortDirection)
+	cfg.respondWithChirpsPage(w, r, filter)
+}
+
+func (cfg *apiConfig) respondWithChirpsPage(w http.ResponseWriter, r *http.Request, filter chirpFilter) {
+	data, err := cfg.listChirps(r.Context(), filter)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chrips", err)
 		return
 	}
 
-	var chrips []Chirp
+	page := ChirpsPage{Chirps: []Chirp{}}
+
+	if len(data) > filter.Limit {
+		data = data[:filter.Limit]
+		last := data[len(data)-1]
+		next := cursor{Time: last.CreatedAt, ID: last.ID}
+		if filter.SortBy == "updated_at" {
+			next.Time = last.UpdatedAt
+		}
+		page.NextCursor = encodeCursor(next)
+	}
 
 	for _, chirp := range data {
-		chrips = append(chrips, Chirp{
-			ID:        chirp.ID,
-			CreatedAt: chirp.CreatedAt,
-			UpdatedAt: chirp.UpdatedAt,
-			Body:      chirp.Body,
-			UserId:    chirp.UserID,
+		page.Chirps = append(page.Chirps, Chirp{
+			ID:           chirp.ID,
+			CreatedAt:    chirp.CreatedAt,
+			UpdatedAt:    chirp.UpdatedAt,
+			Body:         chirp.Body,
+			UserId:       chirp.UserID,
+			LikeCount:    int(chirp.LikeCount),
+			RechirpCount: int(chirp.RechirpCount),
+			InReplyTo:    inReplyTo(chirp.ParentID),
 		})
 	}
 
-	respondWithJSON(w, http.StatusOK, chrips)
+	err = cfg.attachChirpDetails(r.Context(), page.Chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp details", err)
+		return
+	}
+
+	setNextLink(w, r, page.NextCursor)
+	respondWithJSON(w, http.StatusOK, page)
 }
 
 func (cfg *apiConfig) handlerGetChirp(w http.ResponseWriter, r *http.Request) {
@@ -72,18 +228,52 @@ func (cfg *apiConfig) handlerGetChirp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	chirpID := uuid.MustParse(param)
-
-	chirp, err := cfg.db.GetChirpById(context.Background(), chirpID)
+	chirpID, err := uuid.Parse(param)
 	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp id", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirpById(r.Context(), chirpID)
+	if errors.Is(err, sql.ErrNoRows) {
 		respondWithError(w, http.StatusNotFound, "No chirp found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp", err)
+		return
+	}
+
+	if !chirpVisible(r.Context(), chirp) {
+		respondWithError(w, http.StatusNotFound, "No chirp found", nil)
+		return
 	}
 
-	respondWithJSON(w, http.StatusOK, Chirp{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserId:    chirp.UserID,
-	})
+	found := []Chirp{{
+		ID:           chirp.ID,
+		CreatedAt:    chirp.CreatedAt,
+		UpdatedAt:    chirp.UpdatedAt,
+		Body:         chirp.Body,
+		UserId:       chirp.UserID,
+		LikeCount:    int(chirp.LikeCount),
+		RechirpCount: int(chirp.RechirpCount),
+		InReplyTo:    inReplyTo(chirp.ParentID),
+	}}
+
+	err = cfg.attachChirpDetails(r.Context(), found)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp details", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, found[0])
+}
+
+// chirpVisible keeps hidden chirps visible to their author and moderators.
+func chirpVisible(ctx context.Context, chirp database.Chirp) bool {
+	if !chirp.Hidden {
+		return true
+	}
+	user, ok := userFromContext(ctx)
+	return ok && (user.ID == chirp.UserID || hasPermission(user.Role, permModerateChirps))
 }
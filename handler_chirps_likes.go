@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerLikeChirp(w http.ResponseWriter, r *http.Request) {
+	cfg.reactToChirp(w, r, func(ctx context.Context, userID, chirpID uuid.UUID) error {
+		_, err := cfg.db.LikeChirp(ctx, database.LikeChirpParams{UserID: userID, ChirpID: chirpID})
+		return err
+	})
+}
+
+func (cfg *apiConfig) handlerUnlikeChirp(w http.ResponseWriter, r *http.Request) {
+	cfg.reactToChirp(w, r, func(ctx context.Context, userID, chirpID uuid.UUID) error {
+		_, err := cfg.db.UnlikeChirp(ctx, database.UnlikeChirpParams{UserID: userID, ChirpID: chirpID})
+		return err
+	})
+}
+
+func (cfg *apiConfig) handlerRechirp(w http.ResponseWriter, r *http.Request) {
+	cfg.reactToChirp(w, r, func(ctx context.Context, userID, chirpID uuid.UUID) error {
+		_, err := cfg.db.Rechirp(ctx, database.RechirpParams{UserID: userID, ChirpID: chirpID})
+		return err
+	})
+}
+
+func (cfg *apiConfig) handlerUndoRechirp(w http.ResponseWriter, r *http.Request) {
+	cfg.reactToChirp(w, r, func(ctx context.Context, userID, chirpID uuid.UUID) error {
+		_, err := cfg.db.UndoRechirp(ctx, database.UndoRechirpParams{UserID: userID, ChirpID: chirpID})
+		return err
+	})
+}
+
+// reactToChirp treats repeated reactions and missing ones as success.
+func (cfg *apiConfig) reactToChirp(w http.ResponseWriter, r *http.Request, react func(ctx context.Context, userID, chirpID uuid.UUID) error) {
+	user := mustUser(r.Context())
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp id", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirpById(r.Context(), chirpID)
+	if err != nil || !chirpVisible(r.Context(), chirp) {
+		respondWithError(w, http.StatusNotFound, "No chirp found", err)
+		return
+	}
+
+	err = react(r.Context(), user.ID, chirp.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"database/sql"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/RafaelTauschek/http-server/internal/search"
+	"github.com/google/uuid"
+)
+
+const maxSearchQueryLength = 256
+
+// Snippets are HTML escaped before these markers become <mark> tags, so
+// chirp bodies can't inject markup. The query strips the markers from the
+// body so only ts_headline can produce them.
+const (
+	snippetStart     = "\x02"
+	snippetStop      = "\x03"
+	snippetHeadlines = "StartSel=" + snippetStart + ", StopSel=" + snippetStop + ", MaxFragments=2, MaxWords=20, MinWords=5"
+)
+
+type SearchResult struct {
+	Chirp   Chirp   `json:"chirp"`
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+type SearchPage struct {
+	Results    []SearchResult `json:"results"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+func (cfg *apiConfig) handlerSearchChirps(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	if len(q.Get("q")) > maxSearchQueryLength {
+		respondWithError(w, http.StatusBadRequest, "Query is too long", nil)
+		return
+	}
+
+	tsquery, err := search.ParseQuery(q.Get("q"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Query has no searchable terms", err)
+		return
+	}
+
+	params := database.SearchChirpsParams{
+		HeadlineOptions: snippetHeadlines,
+		Query:           tsquery,
+	}
+
+	if id := q.Get("author_id"); id != "" {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid author_id", err)
+			return
+		}
+		params.AuthorID = uuid.NullUUID{UUID: parsed, Valid: true}
+	}
+
+	bounds := []struct {
+		key string
+		dst *sql.NullTime
+	}{
+		{"since", &params.Since},
+		{"until", &params.Until},
+	}
+	for _, b := range bounds {
+		val := q.Get(b.key)
+		if val == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid "+b.key+", expected an RFC 3339 timestamp", err)
+			return
+		}
+		*b.dst = sql.NullTime{Time: parsed.UTC(), Valid: true}
+	}
+
+	if params.Since.Valid && params.Until.Valid && !params.Since.Time.Before(params.Until.Time) {
+		respondWithError(w, http.StatusBadRequest, "since must be before until", nil)
+		return
+	}
+
+	limit, err := parseLimit(q.Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	offset := 0
+	if param := q.Get("cursor"); param != "" {
+		offset, err = decodeOffsetCursor(param)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+			return
+		}
+	}
+
+	params.Limit = int32(limit + 1)
+	params.Offset = int32(offset)
+
+	data, err := cfg.db.SearchChirps(r.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't search chirps", err)
+		return
+	}
+
+	page := SearchPage{Results: []SearchResult{}}
+
+	if len(data) > limit {
+		data = data[:limit]
+		page.NextCursor = encodeOffsetCursor(offset + limit)
+	}
+
+	chirps := make([]Chirp, len(data))
+	for i, row := range data {
+		chirps[i] = Chirp{
+			ID:           row.ID,
+			CreatedAt:    row.CreatedAt,
+			UpdatedAt:    row.UpdatedAt,
+			Body:         row.Body,
+			UserId:       row.UserID,
+			LikeCount:    int(row.LikeCount),
+			RechirpCount: int(row.RechirpCount),
+			InReplyTo:    inReplyTo(row.ParentID),
+		}
+	}
+
+	err = cfg.attachChirpDetails(r.Context(), chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp details", err)
+		return
+	}
+
+	for i, row := range data {
+		page.Results = append(page.Results, SearchResult{
+			Chirp:   chirps[i],
+			Rank:    row.Rank,
+			Snippet: highlightSnippet(row.Snippet),
+		})
+	}
+
+	setNextLink(w, r, page.NextCursor)
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+func highlightSnippet(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, snippetStart, "<mark>")
+	return strings.ReplaceAll(snippet, snippetStop, "</mark>")
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultThreadDepth = 3
+	maxThreadDepth     = 10
+	maxThreadAncestors = 50
+	maxThreadReplies   = 500
+)
+
+// ThreadNode keeps deleted and hidden chirps as placeholders so their
+// replies keep their context.
+type ThreadNode struct {
+	ID          uuid.UUID     `json:"id"`
+	Chirp       *Chirp        `json:"chirp,omitempty"`
+	Unavailable bool          `json:"unavailable,omitempty"`
+	Replies     []*ThreadNode `json:"replies,omitempty"`
+	// MoreReplies is set when replies exist below the requested depth.
+	MoreReplies bool `json:"more_replies,omitempty"`
+}
+
+type Thread struct {
+	Ancestors []*ThreadNode `json:"ancestors"`
+	Chirp     *ThreadNode   `json:"chirp"`
+	// Truncated is set when the thread had more replies than are returned.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+type threadRow struct {
+	chirp database.Chirp
+	depth int
+}
+
+func (cfg *apiConfig) handlerGetThread(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp id", err)
+		return
+	}
+
+	depth := defaultThreadDepth
+	if param := r.URL.Query().Get("depth"); param != "" {
+		depth, err = strconv.Atoi(param)
+		if err != nil || depth < 0 || depth > maxThreadDepth {
+			respondWithError(w, http.StatusBadRequest, "depth must be between 0 and 10", err)
+			return
+		}
+	}
+
+	chirp, err := cfg.db.GetChirpById(r.Context(), chirpID)
+	if err != nil || !chirpVisible(r.Context(), chirp) {
+		respondWithError(w, http.StatusNotFound, "No chirp found", err)
+		return
+	}
+
+	ancestorData, err := cfg.db.ListChirpAncestors(r.Context(), database.ListChirpAncestorsParams{
+		ID:       chirp.ID,
+		MaxDepth: maxThreadAncestors,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve thread", err)
+		return
+	}
+
+	ancestors := make([]threadRow, len(ancestorData))
+	for i, row := range ancestorData {
+		ancestors[i] = threadRow{
+			chirp: database.Chirp{
+				ID:           row.ID,
+				CreatedAt:    row.CreatedAt,
+				UpdatedAt:    row.UpdatedAt,
+				Body:         row.Body,
+				UserID:       row.UserID,
+				Hidden:       row.Hidden,
+				DeletedAt:    row.DeletedAt,
+				DeletedBy:    row.DeletedBy,
+				LikeCount:    row.LikeCount,
+				RechirpCount: row.RechirpCount,
+				ParentID:     row.ParentID,
+			},
+			depth: int(row.Depth),
+		}
+	}
+
+	// Look one level deeper than requested to know where replies go on.
+	descendantData, err := cfg.db.ListChirpDescendants(r.Context(), database.ListChirpDescendantsParams{
+		ID:       chirp.ID,
+		MaxDepth: int32(depth + 1),
+		Limit:    maxThreadReplies + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve thread", err)
+		return
+	}
+
+	thread := Thread{Ancestors: []*ThreadNode{}}
+	if len(descendantData) > maxThreadReplies {
+		descendantData = descendantData[:maxThreadReplies]
+		thread.Truncated = true
+	}
+
+	descendants := make([]threadRow, len(descendantData))
+	for i, row := range descendantData {
+		descendants[i] = threadRow{
+			chirp: database.Chirp{
+				ID:           row.ID,
+				CreatedAt:    row.CreatedAt,
+				UpdatedAt:    row.UpdatedAt,
+				Body:         row.Body,
+				UserID:       row.UserID,
+				Hidden:       row.Hidden,
+				DeletedAt:    row.DeletedAt,
+				DeletedBy:    row.DeletedBy,
+				LikeCount:    row.LikeCount,
+				RechirpCount: row.RechirpCount,
+				ParentID:     row.ParentID,
+			},
+			depth: int(row.Depth),
+		}
+	}
+
+	rows := append([]threadRow{{chirp: chirp}}, ancestors...)
+	for _, row := range descendants {
+		if row.depth <= depth {
+			rows = append(rows, row)
+		}
+	}
+	nodes, err := cfg.threadNodes(r.Context(), rows)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp details", err)
+		return
+	}
+
+	for _, row := range ancestors {
+		thread.Ancestors = append(thread.Ancestors, nodes[row.chirp.ID])
+	}
+
+	thread.Chirp = nodes[chirp.ID]
+	linkReplies(nodes, descendants, depth)
+	pruneUnavailable(thread.Chirp)
+
+	respondWithJSON(w, http.StatusOK, thread)
+}
+
+func (cfg *apiConfig) threadNodes(ctx context.Context, rows []threadRow) (map[uuid.UUID]*ThreadNode, error) {
+	var available []Chirp
+	for _, row := range rows {
+		c := row.chirp
+		if c.DeletedAt.Valid || !chirpVisible(ctx, c) {
+			continue
+		}
+		available = append(available, Chirp{
+			ID:           c.ID,
+			CreatedAt:    c.CreatedAt,
+			UpdatedAt:    c.UpdatedAt,
+			Body:         c.Body,
+			UserId:       c.UserID,
+			InReplyTo:    inReplyTo(c.ParentID),
+			LikeCount:    int(c.LikeCount),
+			RechirpCount: int(c.RechirpCount),
+		})
+	}
+
+	err := cfg.attachChirpDetails(ctx, available)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[uuid.UUID]*ThreadNode, len(rows))
+	for _, row := range rows {
+		nodes[row.chirp.ID] = &ThreadNode{ID: row.chirp.ID, Unavailable: true}
+	}
+	for i := range available {
+		node := nodes[available[i].ID]
+		node.Chirp = &available[i]
+		node.Unavailable = false
+	}
+
+	return nodes, nil
+}
+
+// linkReplies only marks rows deeper than depth as more replies.
+func linkReplies(nodes map[uuid.UUID]*ThreadNode, descendants []threadRow, depth int) {
+	for _, row := range descendants {
+		parent := nodes[row.chirp.ParentID.UUID]
+		if parent == nil {
+			// Its parent was cut off by maxThreadReplies.
+			continue
+		}
+		if row.depth > depth {
+			parent.MoreReplies = true
+			continue
+		}
+		parent.Replies = append(parent.Replies, nodes[row.chirp.ID])
+	}
+}
+
+// pruneUnavailable drops placeholders with nothing below them.
+func pruneUnavailable(node *ThreadNode) {
+	kept := node.Replies[:0]
+	for _, reply := range node.Replies {
+		pruneUnavailable(reply)
+		if reply.Unavailable && len(reply.Replies) == 0 && !reply.MoreReplies {
+			continue
+		}
+		kept = append(kept, reply)
+	}
+	node.Replies = kept
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestLinkReplies(t *testing.T) {
+	root := uuid.New()
+	reply := uuid.New()
+	deleted := uuid.New()
+	deletedReply := uuid.New()
+	tooDeep := uuid.New()
+	orphan := uuid.New()
+	emptyDeleted := uuid.New()
+
+	row := func(id, parent uuid.UUID, depth int) threadRow {
+		return threadRow{
+			chirp: database.Chirp{ID: id, ParentID: uuid.NullUUID{UUID: parent, Valid: true}},
+			depth: depth,
+		}
+	}
+
+	tests := []struct {
+		name             string
+		descendants      []threadRow
+		unavailable      []uuid.UUID
+		depth            int
+		expectedReplies  map[uuid.UUID]int
+		expectedMore     map[uuid.UUID]bool
+		expectedRootKids []uuid.UUID
+	}{
+		{
+			name:             "replies are nested under their parent",
+			descendants:      []threadRow{row(reply, root, 1), row(deletedReply, reply, 2)},
+			depth:            3,
+			expectedReplies:  map[uuid.UUID]int{root: 1, reply: 1},
+			expectedRootKids: []uuid.UUID{reply},
+		},
+		{
+			name:             "replies below depth only set more_replies",
+			descendants:      []threadRow{row(reply, root, 1), row(tooDeep, reply, 2)},
+			depth:            1,
+			expectedReplies:  map[uuid.UUID]int{root: 1, reply: 0},
+			expectedMore:     map[uuid.UUID]bool{reply: true},
+			expectedRootKids: []uuid.UUID{reply},
+		},
+		{
+			name:             "rows whose parent was cut off are skipped",
+			descendants:      []threadRow{row(reply, root, 1), row(orphan, uuid.New(), 2)},
+			depth:            3,
+			expectedReplies:  map[uuid.UUID]int{root: 1},
+			expectedRootKids: []uuid.UUID{reply},
+		},
+		{
+			name:             "unavailable chirps with replies stay as placeholders",
+			descendants:      []threadRow{row(deleted, root, 1), row(deletedReply, deleted, 2), row(emptyDeleted, root, 1)},
+			unavailable:      []uuid.UUID{deleted, emptyDeleted},
+			depth:            3,
+			expectedReplies:  map[uuid.UUID]int{root: 1, deleted: 1},
+			expectedRootKids: []uuid.UUID{deleted},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := map[uuid.UUID]*ThreadNode{root: {ID: root}}
+			for _, row := range tt.descendants {
+				nodes[row.chirp.ID] = &ThreadNode{ID: row.chirp.ID}
+			}
+			for _, id := range tt.unavailable {
+				nodes[id].Unavailable = true
+			}
+
+			linkReplies(nodes, tt.descendants, tt.depth)
+			pruneUnavailable(nodes[root])
+
+			for id, want := range tt.expectedReplies {
+				if got := len(nodes[id].Replies); got != want {
+					t.Errorf("node %s has %d replies, want %d", id, got, want)
+				}
+			}
+			for id, node := range nodes {
+				if node.MoreReplies != tt.expectedMore[id] {
+					t.Errorf("node %s has more_replies %v, want %v", id, node.MoreReplies, tt.expectedMore[id])
+				}
+			}
+
+			kids := nodes[root].Replies
+			if len(kids) != len(tt.expectedRootKids) {
+				t.Fatalf("root has %d replies, want %d", len(kids), len(tt.expectedRootKids))
+			}
+			for i, id := range tt.expectedRootKids {
+				if kids[i].ID != id {
+					t.Errorf("reply %d is %s, want %s", i, kids[i].ID, id)
+				}
+			}
+		})
+	}
+}
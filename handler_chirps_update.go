@@ -0,0 +1,157 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/google/uuid"
+)
+
+type ChirpRevision struct {
+	ID         uuid.UUID `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	ReplacedAt time.Time `json:"replaced_at"`
+	Body       string    `json:"body"`
+}
+
+var errNotChirpAuthor = errors.New("not the author of the chirp")
+
+// handlerUpdateChirp keeps the previous body as a revision.
+func (cfg *apiConfig) handlerUpdateChirp(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Body string `json:"body"`
+	}
+
+	user := mustUser(r.Context())
+	userPlan, err := cfg.planFor(r.Context(), user)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load plan", err)
+		return
+	}
+	if !userPlan.EditChirps {
+		respondWithError(w, http.StatusForbidden, "Your plan does not allow editing chirps", nil)
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp id", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	result, ok := cfg.checkChirpBody(w, userPlan, params.Body)
+	if !ok {
+		return
+	}
+
+	var chirp database.Chirp
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		previous, err := q.GetChirpForUpdate(r.Context(), chirpID)
+		if err != nil {
+			return err
+		}
+		if previous.UserID != user.ID {
+			return errNotChirpAuthor
+		}
+
+		err = q.CreateChirpRevision(r.Context(), database.CreateChirpRevisionParams{
+			CreatedAt: previous.UpdatedAt,
+			ChirpID:   previous.ID,
+			Body:      previous.Body,
+		})
+		if err != nil {
+			return err
+		}
+
+		chirp, err = q.UpdateChirpBody(r.Context(), database.UpdateChirpBodyParams{
+			ID:   previous.ID,
+			Body: result.Text,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = saveChirpEntities(r.Context(), q, chirp.ID, chirp.Body)
+		if err != nil {
+			return err
+		}
+		return reportFlaggedChirp(r.Context(), q, chirp.ID, result)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "No chirp found", err)
+		return
+	}
+	if errors.Is(err, errNotChirpAuthor) {
+		respondWithError(w, http.StatusForbidden, "Only the author can edit a chirp", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+
+	updated := []Chirp{{
+		ID:           chirp.ID,
+		CreatedAt:    chirp.CreatedAt,
+		UpdatedAt:    chirp.UpdatedAt,
+		Body:         chirp.Body,
+		UserId:       chirp.UserID,
+		LikeCount:    int(chirp.LikeCount),
+		RechirpCount: int(chirp.RechirpCount),
+		InReplyTo:    inReplyTo(chirp.ParentID),
+
+		Moderation: newModerationReport(result),
+	}}
+
+	err = cfg.attachChirpDetails(r.Context(), updated)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp details", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, updated[0])
+}
+
+func (cfg *apiConfig) handlerListChirpRevisions(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp id", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirpById(r.Context(), chirpID)
+	if err != nil || !chirpVisible(r.Context(), chirp) {
+		respondWithError(w, http.StatusNotFound, "No chirp found", err)
+		return
+	}
+
+	data, err := cfg.db.ListChirpRevisions(r.Context(), chirp.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve revisions", err)
+		return
+	}
+
+	revisions := []ChirpRevision{}
+	for _, revision := range data {
+		revisions = append(revisions, ChirpRevision{
+			ID:         revision.ID,
+			CreatedAt:  revision.CreatedAt,
+			ReplacedAt: revision.ReplacedAt,
+			Body:       revision.Body,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, revisions)
+}
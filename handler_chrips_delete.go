@@ -1,49 +1,95 @@
 package main
 
 import (
-	"context"
+	"database/sql"
 	"errors"
 	"net/http"
 
-	"github.com/RafaelTauschek/http-server/internal/auth"
+	"github.com/RafaelTauschek/http-server/internal/database"
 	"github.com/google/uuid"
 )
 
 func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, r *http.Request) {
-	param := r.PathValue("chirpID")
+	user := mustUser(r.Context())
 
-	if param == "" {
-		respondWithError(w, http.StatusBadRequest, "No parameter provided", nil)
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp id", err)
 		return
 	}
 
-	token, err := auth.GetBearerToken(r.Header)
+	chirp, err := cfg.db.GetChirpById(r.Context(), chirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "No chirp found", err)
+		return
+	}
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Couldn't authenticate token", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp", err)
 		return
 	}
 
-	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if chirp.UserID != user.ID && !hasPermission(user.Role, permModerateChirps) {
+		respondWithError(w, http.StatusForbidden, "UserId can't delete chrips from other users", errors.New("not allowed"))
+		return
+	}
+
+	deleted, err := cfg.db.DeleteChirp(r.Context(), database.DeleteChirpParams{
+		ID:        chirp.ID,
+		DeletedBy: uuid.NullUUID{UUID: user.ID, Valid: true},
+	})
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		respondWithError(w, http.StatusInternalServerError, "Coudln't delete chirp", err)
 		return
 	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "No chirp found", nil)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// handlerRestoreChirp undoes a delete made by the author within the restore
+// window. Chirps removed by a moderator can't be restored this way.
+func (cfg *apiConfig) handlerRestoreChirp(w http.ResponseWriter, r *http.Request) {
+	user := mustUser(r.Context())
 
-	chirp, err := cfg.db.GetChirpById(r.Context(), uuid.MustParse(param))
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, "Couldn't retrieve chirp", err)
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp id", err)
 		return
 	}
 
-	if chirp.UserID != userID {
-		respondWithError(w, http.StatusForbidden, "UserId can't delete chrips from other users", errors.New("not allowed"))
+	chirp, err := cfg.db.RestoreChirp(r.Context(), database.RestoreChirpParams{
+		ID:                   chirpID,
+		UserID:               user.ID,
+		RestoreWindowSeconds: int32(cfg.restoreWindow.Seconds()),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "No restorable chirp found", err)
 		return
 	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't restore chirp", err)
+		return
+	}
+
+	restored := []Chirp{{
+		ID:           chirp.ID,
+		CreatedAt:    chirp.CreatedAt,
+		UpdatedAt:    chirp.UpdatedAt,
+		Body:         chirp.Body,
+		UserId:       chirp.UserID,
+		LikeCount:    int(chirp.LikeCount),
+		RechirpCount: int(chirp.RechirpCount),
+		InReplyTo:    inReplyTo(chirp.ParentID),
+	}}
 
-	err = cfg.db.DeleteChirp(context.Background(), chirp.ID)
+	err = cfg.attachChirpDetails(r.Context(), restored)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Coudln't delete chirp", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't load chirp details", err)
+		return
 	}
 
-	respondWithJSON(w, http.StatusNoContent, nil)
+	respondWithJSON(w, http.StatusOK, restored[0])
 }
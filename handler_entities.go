@@ -0,0 +1,49 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerHashtagChirps(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	query.Set("hashtag", r.PathValue("tag"))
+
+	filter, err := parseChirpFilter(query)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid parameters: "+err.Error(), err)
+		return
+	}
+
+	cfg.respondWithChirpsPage(w, r, filter)
+}
+
+func (cfg *apiConfig) handlerUserMentions(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user id", err)
+		return
+	}
+
+	_, err = cfg.db.GetUserByID(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Couldn't find user", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+		return
+	}
+
+	filter, err := parseChirpFilter(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid parameters: "+err.Error(), err)
+		return
+	}
+	filter.MentionedUserID = uuid.NullUUID{UUID: userID, Valid: true}
+
+	cfg.respondWithChirpsPage(w, r, filter)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/google/uuid"
+)
+
+// FollowUser leaves out the email since follow lists are public.
+type FollowUser struct {
+	ID         uuid.UUID `json:"id"`
+	Handle     string    `json:"handle,omitempty"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+type FollowPage struct {
+	Users      []FollowUser `json:"users"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+type cursorParams struct {
+	time sql.NullTime
+	id   uuid.NullUUID
+}
+
+func (cfg *apiConfig) followTarget(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user id", err)
+		return uuid.Nil, false
+	}
+
+	_, err = cfg.db.GetUserByID(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Couldn't find user", err)
+		return uuid.Nil, false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+func (cfg *apiConfig) handlerFollow(w http.ResponseWriter, r *http.Request) {
+	user := mustUser(r.Context())
+
+	followee, ok := cfg.followTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if followee == user.ID {
+		respondWithError(w, http.StatusBadRequest, "You can't follow yourself", nil)
+		return
+	}
+
+	err := cfg.db.CreateFollow(r.Context(), database.CreateFollowParams{
+		FollowerID: user.ID,
+		FolloweeID: followee,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't follow user", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func (cfg *apiConfig) handlerUnfollow(w http.ResponseWriter, r *http.Request) {
+	user := mustUser(r.Context())
+
+	followee, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user id", err)
+		return
+	}
+
+	err = cfg.db.DeleteFollow(r.Context(), database.DeleteFollowParams{
+		FollowerID: user.ID,
+		FolloweeID: followee,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't unfollow user", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func (cfg *apiConfig) handlerListFollowers(w http.ResponseWriter, r *http.Request) {
+	cfg.respondWithFollowPage(w, r, func(ctx context.Context, userID uuid.UUID, after cursorParams, limit int32) ([]FollowUser, error) {
+		data, err := cfg.db.ListFollowers(ctx, database.ListFollowersParams{
+			UserID:     userID,
+			CursorTime: after.time,
+			CursorID:   after.id,
+			Limit:      limit,
+		})
+		users := make([]FollowUser, len(data))
+		for i, row := range data {
+			users[i] = FollowUser{ID: row.ID, Handle: row.Handle.String, FollowedAt: row.FollowedAt}
+		}
+		return users, err
+	})
+}
+
+func (cfg *apiConfig) handlerListFollowing(w http.ResponseWriter, r *http.Request) {
+	cfg.respondWithFollowPage(w, r, func(ctx context.Context, userID uuid.UUID, after cursorParams, limit int32) ([]FollowUser, error) {
+		data, err := cfg.db.ListFollowing(ctx, database.ListFollowingParams{
+			UserID:     userID,
+			CursorTime: after.time,
+			CursorID:   after.id,
+			Limit:      limit,
+		})
+		users := make([]FollowUser, len(data))
+		for i, row := range data {
+			users[i] = FollowUser{ID: row.ID, Handle: row.Handle.String, FollowedAt: row.FollowedAt}
+		}
+		return users, err
+	})
+}
+
+func (cfg *apiConfig) respondWithFollowPage(w http.ResponseWriter, r *http.Request, list func(ctx context.Context, userID uuid.UUID, after cursorParams, limit int32) ([]FollowUser, error)) {
+	userID, ok := cfg.followTarget(w, r)
+	if !ok {
+		return
+	}
+
+	limit, err := parseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	var after cursorParams
+	if param := r.URL.Query().Get("cursor"); param != "" {
+		c, err := decodeCursor(param)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+			return
+		}
+		after = cursorParams{
+			time: sql.NullTime{Time: c.Time, Valid: true},
+			id:   uuid.NullUUID{UUID: c.ID, Valid: true},
+		}
+	}
+
+	users, err := list(r.Context(), userID, after, int32(limit+1))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve users", err)
+		return
+	}
+
+	page := FollowPage{Users: users}
+	if len(users) > limit {
+		page.Users = users[:limit]
+		last := page.Users[len(page.Users)-1]
+		page.NextCursor = encodeCursor(cursor{Time: last.FollowedAt, ID: last.ID})
+	}
+
+	setNextLink(w, r, page.NextCursor)
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// handlerTimeline shows the newest chirps first unless sort says otherwise.
+func (cfg *apiConfig) handlerTimeline(w http.ResponseWriter, r *http.Request) {
+	user := mustUser(r.Context())
+
+	query := r.URL.Query()
+	if !query.Has("sort") {
+		query.Set("sort", "desc")
+	}
+
+	filter, err := parseChirpFilter(query)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid parameters: "+err.Error(), err)
+		return
+	}
+	filter.TimelineOf = uuid.NullUUID{UUID: user.ID, Valid: true}
+
+	cfg.respondWithChirpsPage(w, r, filter)
+}
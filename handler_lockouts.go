@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+type Lockout struct {
+	Key         string    `json:"key"`
+	Failures    int32     `json:"failures"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+func (cfg *apiConfig) handlerListLockouts(w http.ResponseWriter, r *http.Request) {
+	data, err := cfg.db.ListActiveLockouts(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve lockouts", err)
+		return
+	}
+
+	lockouts := []Lockout{}
+	for _, lockout := range data {
+		lockouts = append(lockouts, Lockout{
+			Key:         lockout.Key,
+			Failures:    lockout.Failures,
+			LockedUntil: lockout.LockedUntil.Time,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, lockouts)
+}
+
+func (cfg *apiConfig) handlerClearLockout(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+
+	rows, err := cfg.db.ClearLockout(r.Context(), key)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't clear lockout", err)
+		return
+	}
+
+	if rows == 0 {
+		respondWithError(w, http.StatusNotFound, "No lockout found", nil)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/RafaelTauschek/http-server/internal/auth"
 	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/google/uuid"
 )
 
 func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
@@ -24,19 +27,42 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ip := cfg.clientIP(r)
+
+	lockedFor, err := cfg.loginLockedFor(r.Context(), params.Email, ip)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't check login attempts", err)
+		return
+	}
+
+	if lockedFor > 0 {
+		setRetryAfter(w, lockedFor)
+		respondWithError(w, http.StatusTooManyRequests, "Too many failed login attempts", nil)
+		return
+	}
+
 	user, err := cfg.db.GetUserByEmail(context.Background(), params.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		auth.CheckPasswordHash(params.Password, dummyPasswordHash())
+		cfg.recordLoginFailure(r, params.Email, ip, uuid.NullUUID{})
+		respondWithError(w, http.StatusUnauthorized, "Incorrect email or password", err)
+		return
+	}
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Couldn't find user with the provided email", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
 		return
 	}
 
 	err = auth.CheckPasswordHash(params.Password, user.HashedPassword)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Password don't match", err)
+		cfg.recordLoginFailure(r, params.Email, ip, uuid.NullUUID{UUID: user.ID, Valid: true})
+		respondWithError(w, http.StatusUnauthorized, "Incorrect email or password", err)
 		return
 	}
+	setRequestUser(r, user.ID)
+	cfg.recordLoginSuccess(r, params.Email, ip, user.ID)
 
-	token, err := auth.MakeJWT(user.ID, cfg.secret)
+	token, err := auth.MakeJWT(user.ID, user.Role, cfg.keys, cfg.accessTokenTTL)
 
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't create token", err)
@@ -50,8 +76,11 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 	}
 
 	_, err = cfg.db.CreateRefreshToken(context.Background(), database.CreateRefreshTokenParams{
-		Token:  refreshToken,
-		UserID: user.ID,
+		Token:     refreshToken,
+		UserID:    user.ID,
+		FamilyID:  uuid.New(),
+		UserAgent: r.UserAgent(),
+		Ip:        ip,
 	})
 
 	if err != nil {
@@ -59,12 +88,21 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	subscription, err := cfg.userSubscription(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve subscription", err)
+		return
+	}
+
 	respondWithJSON(w, http.StatusOK, User{
 		ID:           user.ID,
 		CreatedAt:    user.CreatedAt,
 		UpdatedAt:    user.UpdatedAt,
 		Email:        user.Email,
+		Handle:       user.Handle.String,
 		IsChirpyRed:  user.IsChirpyRed,
+		Subscription: subscription,
+		Role:         user.Role,
 		Token:        token,
 		RefreshToken: refreshToken,
 	})
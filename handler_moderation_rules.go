@@ -0,0 +1,165 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/RafaelTauschek/http-server/internal/moderation"
+	"github.com/google/uuid"
+)
+
+type ModerationRule struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Term      string    `json:"term"`
+	Action    string    `json:"action"`
+}
+
+func moderationRuleFromDB(rule database.ModerationRule) ModerationRule {
+	return ModerationRule{
+		ID:        rule.ID,
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+		Term:      rule.Term,
+		Action:    rule.Action,
+	}
+}
+
+type moderationRuleParameters struct {
+	Term   string `json:"term"`
+	Action string `json:"action"`
+}
+
+func decodeModerationRule(r *http.Request) (moderationRuleParameters, error) {
+	decoder := json.NewDecoder(r.Body)
+	params := moderationRuleParameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		return params, err
+	}
+
+	params.Term = strings.TrimSpace(params.Term)
+	if params.Term == "" || strings.ContainsFunc(params.Term, func(r rune) bool { return r == ' ' || r == '\n' || r == '\t' }) {
+		return params, errors.New("term must be a single word")
+	}
+
+	if !moderation.Action(params.Action).Valid() {
+		return params, errors.New("action must be mask, flag or reject")
+	}
+
+	return params, nil
+}
+
+func (cfg *apiConfig) handlerListModerationRules(w http.ResponseWriter, r *http.Request) {
+	data, err := cfg.db.ListModerationRules(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve rules", err)
+		return
+	}
+
+	rules := []ModerationRule{}
+	for _, rule := range data {
+		rules = append(rules, moderationRuleFromDB(rule))
+	}
+
+	respondWithJSON(w, http.StatusOK, rules)
+}
+
+func (cfg *apiConfig) handlerCreateModerationRule(w http.ResponseWriter, r *http.Request) {
+	params, err := decodeModerationRule(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	rule, err := cfg.db.CreateModerationRule(r.Context(), database.CreateModerationRuleParams{
+		Term:   params.Term,
+		Action: params.Action,
+	})
+	if isUniqueViolation(err, "moderation_rules_term_key") {
+		respondWithError(w, http.StatusConflict, "A rule for this term already exists", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create rule", err)
+		return
+	}
+
+	if err := cfg.reloadModeration(r.Context()); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reload moderation rules", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, moderationRuleFromDB(rule))
+}
+
+func (cfg *apiConfig) handlerUpdateModerationRule(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := uuid.Parse(r.PathValue("ruleID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid rule id", err)
+		return
+	}
+
+	params, err := decodeModerationRule(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	rule, err := cfg.db.UpdateModerationRule(r.Context(), database.UpdateModerationRuleParams{
+		ID:     ruleID,
+		Term:   params.Term,
+		Action: params.Action,
+	})
+	if isUniqueViolation(err, "moderation_rules_term_key") {
+		respondWithError(w, http.StatusConflict, "A rule for this term already exists", err)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "No rule found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update rule", err)
+		return
+	}
+
+	if err := cfg.reloadModeration(r.Context()); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reload moderation rules", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, moderationRuleFromDB(rule))
+}
+
+func (cfg *apiConfig) handlerDeleteModerationRule(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := uuid.Parse(r.PathValue("ruleID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid rule id", err)
+		return
+	}
+
+	rows, err := cfg.db.DeleteModerationRule(r.Context(), ruleID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete rule", err)
+		return
+	}
+
+	if rows == 0 {
+		respondWithError(w, http.StatusNotFound, "No rule found", nil)
+		return
+	}
+
+	if err := cfg.reloadModeration(r.Context()); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reload moderation rules", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
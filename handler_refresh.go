@@ -2,17 +2,23 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/RafaelTauschek/http-server/internal/auth"
+	"github.com/RafaelTauschek/http-server/internal/database"
 )
 
+var errRefreshTokenReused = errors.New("refresh token was already rotated")
+
 func (cfg *apiConfig) handlerRefreshToken(w http.ResponseWriter, r *http.Request) {
 
 	type returnVals struct {
-		Token string `json:"token"`
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
 	}
 
 	refreshToken, err := auth.GetBearerToken(r.Header)
@@ -27,6 +33,12 @@ func (cfg *apiConfig) handlerRefreshToken(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if token.ReplacedBy.Valid {
+		cfg.revokeTokenFamily(r.Context(), token)
+		respondWithError(w, http.StatusUnauthorized, "Couldn't authorize token", errRefreshTokenReused)
+		return
+	}
+
 	if token.ExpiresAt.Compare(time.Now()) == -1 {
 		respondWithError(w, http.StatusUnauthorized, "Couldn't authorize token", errors.New("token is expired"))
 		return
@@ -36,14 +48,72 @@ func (cfg *apiConfig) handlerRefreshToken(w http.ResponseWriter, r *http.Request
 		respondWithError(w, http.StatusUnauthorized, "Couldn't authorize token", errors.New("token is revoked"))
 		return
 	}
+	setRequestUser(r, token.UserID)
+
+	newRefreshToken, err := auth.MakeRefreshToken()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create refresh token", err)
+		return
+	}
+
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		_, err := q.RotateRefreshToken(r.Context(), database.RotateRefreshTokenParams{
+			Token:      token.Token,
+			ReplacedBy: sql.NullString{String: newRefreshToken, Valid: true},
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			// Another request rotated or revoked the token since we read it.
+			return errRefreshTokenReused
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateRefreshToken(r.Context(), database.CreateRefreshTokenParams{
+			Token:     newRefreshToken,
+			UserID:    token.UserID,
+			FamilyID:  token.FamilyID,
+			UserAgent: r.UserAgent(),
+			Ip:        cfg.clientIP(r),
+		})
+		return err
+	})
+	if errors.Is(err, errRefreshTokenReused) {
+		cfg.revokeTokenFamily(r.Context(), token)
+		respondWithError(w, http.StatusUnauthorized, "Couldn't authorize token", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't rotate refresh token", err)
+		return
+	}
 
-	jwtToken, err := auth.MakeJWT(token.UserID, cfg.secret)
+	user, err := cfg.db.GetUserByID(r.Context(), token.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+		return
+	}
+
+	jwtToken, err := auth.MakeJWT(user.ID, user.Role, cfg.keys, cfg.accessTokenTTL)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't create token", err)
 		return
 	}
 
 	respondWithJSON(w, http.StatusOK, returnVals{
-		Token: jwtToken,
+		Token:        jwtToken,
+		RefreshToken: newRefreshToken,
 	})
 }
+
+// revokeTokenFamily is called when a rotated refresh token is presented
+// again. Either the client or an attacker holds a stolen copy, and we can't
+// tell which, so every token descended from the same login is revoked.
+func (cfg *apiConfig) revokeTokenFamily(ctx context.Context, token database.RefreshToken) {
+	slog.Warn("Refresh token reuse detected, revoking token family", "user_id", token.UserID, "family_id", token.FamilyID)
+
+	err := cfg.db.RevokeTokenFamily(ctx, token.FamilyID)
+	if err != nil {
+		slog.Error("Couldn't revoke token family", "family_id", token.FamilyID, "error", err)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/google/uuid"
+)
+
+const maxReportReasonLength = 500
+
+type Report struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	Reason    string    `json:"reason"`
+	Status    string    `json:"status"`
+}
+
+type ReportedChirp struct {
+	Chirp           Chirp     `json:"chirp"`
+	Hidden          bool      `json:"hidden"`
+	ReportCount     int64     `json:"report_count"`
+	Reasons         []string  `json:"reasons"`
+	FirstReportedAt time.Time `json:"first_reported_at"`
+}
+
+func (cfg *apiConfig) handlerReportChirp(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Reason string `json:"reason"`
+	}
+
+	user := mustUser(r.Context())
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp id", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	params.Reason = strings.TrimSpace(params.Reason)
+	if params.Reason == "" || len(params.Reason) > maxReportReasonLength {
+		respondWithError(w, http.StatusBadRequest, "Reason must be between 1 and 500 characters", nil)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirpById(r.Context(), chirpID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && chirp.Hidden) {
+		respondWithError(w, http.StatusNotFound, "No chirp found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp", err)
+		return
+	}
+
+	report, err := cfg.db.CreateReport(r.Context(), database.CreateReportParams{
+		ChirpID:    chirp.ID,
+		ReporterID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		Reason:     params.Reason,
+	})
+	if isUniqueViolation(err, "reports_open_reporter_idx") {
+		respondWithError(w, http.StatusConflict, "You already reported this chirp", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create report", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, Report{
+		ID:        report.ID,
+		CreatedAt: report.CreatedAt,
+		ChirpID:   report.ChirpID,
+		Reason:    report.Reason,
+		Status:    report.Status,
+	})
+}
+
+func (cfg *apiConfig) handlerReportQueue(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	data, err := cfg.db.ListReportQueue(r.Context(), int32(limit))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve reports", err)
+		return
+	}
+
+	queue := []ReportedChirp{}
+	for _, item := range data {
+		queue = append(queue, ReportedChirp{
+			Chirp: Chirp{
+				ID:           item.ID,
+				CreatedAt:    item.CreatedAt,
+				UpdatedAt:    item.UpdatedAt,
+				Body:         item.Body,
+				UserId:       item.UserID,
+				LikeCount:    int(item.LikeCount),
+				RechirpCount: int(item.RechirpCount),
+				InReplyTo:    inReplyTo(item.ParentID),
+			},
+			Hidden:          item.Hidden,
+			ReportCount:     item.ReportCount,
+			Reasons:         item.Reasons,
+			FirstReportedAt: item.FirstReportedAt,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, queue)
+}
+
+func (cfg *apiConfig) handlerResolveReports(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Action string `json:"action"`
+	}
+
+	moderator := mustUser(r.Context())
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp id", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	status := map[string]string{
+		"dismiss": "dismissed",
+		"hide":    "hidden",
+		"show":    "shown",
+		"delete":  "deleted",
+	}
+
+	if _, ok := status[params.Action]; !ok {
+		respondWithError(w, http.StatusBadRequest, "Action must be dismiss, hide, show or delete", nil)
+		return
+	}
+
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		var changed int64
+		var err error
+		switch params.Action {
+		case "hide", "show":
+			changed, err = q.SetChirpHidden(r.Context(), database.SetChirpHiddenParams{
+				ID:     chirpID,
+				Hidden: params.Action == "hide",
+			})
+		case "delete":
+			changed, err = q.DeleteChirp(r.Context(), database.DeleteChirpParams{
+				ID:        chirpID,
+				DeletedBy: uuid.NullUUID{UUID: moderator.ID, Valid: true},
+			})
+		}
+		if err != nil {
+			return err
+		}
+		if params.Action != "dismiss" && changed == 0 {
+			return sql.ErrNoRows
+		}
+
+		resolved, err := q.ResolveReports(r.Context(), database.ResolveReportsParams{
+			ChirpID:    chirpID,
+			Status:     status[params.Action],
+			ResolvedBy: uuid.NullUUID{UUID: moderator.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		if params.Action == "dismiss" && resolved == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+	if errors.Is(err, sql.ErrNoRows) && params.Action == "dismiss" {
+		respondWithError(w, http.StatusNotFound, "No open reports found", err)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "No chirp found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve reports", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/RafaelTauschek/http-server/internal/auth"
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/google/uuid"
+)
+
+// Session is one login: the chain of refresh tokens produced by rotating
+// the token handed out at login. Its ID is that of the newest token, but
+// the ID of any earlier token in the chain still identifies the session.
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	StartedAt  time.Time `json:"started_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+}
+
+func (cfg *apiConfig) handlerListSessions(w http.ResponseWriter, r *http.Request) {
+	user := mustUser(r.Context())
+
+	data, err := cfg.db.ListActiveSessions(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve sessions", err)
+		return
+	}
+
+	sessions := []Session{}
+	for _, session := range data {
+		sessions = append(sessions, Session{
+			ID:         session.ID,
+			StartedAt:  session.StartedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			UserAgent:  session.UserAgent,
+			IP:         session.Ip,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, sessions)
+}
+
+func (cfg *apiConfig) handlerRevokeSession(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid session id", err)
+		return
+	}
+
+	user := mustUser(r.Context())
+
+	session, err := cfg.db.GetRefreshTokenByID(r.Context(), database.GetRefreshTokenByIDParams{
+		ID:     sessionID,
+		UserID: user.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "No session found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve session", err)
+		return
+	}
+
+	err = cfg.db.RevokeTokenFamily(r.Context(), session.FamilyID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke session", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// handlerRevokeOtherSessions is authenticated with the refresh token, like
+// /api/revoke, because that is what identifies the session to keep.
+func (cfg *apiConfig) handlerRevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	refreshToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no token provided", err)
+		return
+	}
+
+	token, err := cfg.db.GetUserFromRefreshToken(r.Context(), refreshToken)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't authorize token", err)
+		return
+	}
+
+	if token.RevokedAt.Valid || token.ExpiresAt.Before(time.Now()) {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't authorize token", errors.New("token is not active"))
+		return
+	}
+	setRequestUser(r, token.UserID)
+
+	err = cfg.db.RevokeOtherSessions(r.Context(), database.RevokeOtherSessionsParams{
+		UserID:   token.UserID,
+		FamilyID: token.FamilyID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
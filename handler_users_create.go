@@ -2,29 +2,35 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/RafaelTauschek/http-server/internal/auth"
 	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/RafaelTauschek/http-server/internal/entities"
 	"github.com/google/uuid"
 )
 
 type User struct {
-	ID           uuid.UUID `json:"id"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	Email        string    `json:"email"`
-	IsChirpyRed  bool      `json:"is_chirpy_red"`
-	Token        string    `json:"token"`
-	RefreshToken string    `json:"refresh_token"`
+	ID           uuid.UUID     `json:"id"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+	Email        string        `json:"email"`
+	Handle       string        `json:"handle,omitempty"`
+	IsChirpyRed  bool          `json:"is_chirpy_red"`
+	Subscription *Subscription `json:"subscription,omitempty"`
+	Role         string        `json:"role"`
+	Token        string        `json:"token"`
+	RefreshToken string        `json:"refresh_token"`
 }
 
 func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, r *http.Request) {
 	type parameters struct {
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		Handle   string `json:"handle"`
 	}
 
 	decoder := json.NewDecoder(r.Body)
@@ -35,6 +41,11 @@ func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	handle, ok := parseHandle(w, params.Handle)
+	if !ok {
+		return
+	}
+
 	hashedPassword, err := auth.HashPassword(params.Password)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to hash password", err)
@@ -44,8 +55,17 @@ func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, r *http.Request)
 	user, err := cfg.db.CreateUser(context.Background(), database.CreateUserParams{
 		Email:          params.Email,
 		HashedPassword: hashedPassword,
+		Role:           roleUser,
+		Handle:         handle,
 	})
-
+	if isUniqueViolation(err, "users_email_idx") {
+		respondWithError(w, http.StatusConflict, "Email is already registered", err)
+		return
+	}
+	if isUniqueViolation(err, "users_handle_idx") {
+		respondWithError(w, http.StatusConflict, "Handle is already taken", err)
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't create user", err)
 		return
@@ -56,6 +76,19 @@ func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, r *http.Request)
 		CreatedAt:   user.CreatedAt,
 		UpdatedAt:   user.UpdatedAt,
 		Email:       user.Email,
+		Handle:      user.Handle.String,
 		IsChirpyRed: user.IsChirpyRed,
+		Role:        user.Role,
 	})
 }
+
+func parseHandle(w http.ResponseWriter, handle string) (sql.NullString, bool) {
+	if handle == "" {
+		return sql.NullString{}, true
+	}
+	if !entities.ValidHandle(handle) {
+		respondWithError(w, http.StatusBadRequest, "Handle must be 3 to 30 letters, digits or underscores", nil)
+		return sql.NullString{}, false
+	}
+	return sql.NullString{String: handle, Valid: true}, true
+}
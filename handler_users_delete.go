@@ -0,0 +1,103 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerDeleteUser(w http.ResponseWriter, r *http.Request) {
+	user := mustUser(r.Context())
+
+	err := cfg.withTx(r.Context(), func(q *database.Queries) error {
+		deleted, err := q.DeleteUser(r.Context(), user.ID)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteUserChirps(r.Context(), database.DeleteUserChirpsParams{
+			UserID:    deleted.ID,
+			DeletedAt: deleted.DeletedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.RecountUserReactions(r.Context(), deleted.ID)
+		if err != nil {
+			return err
+		}
+
+		return q.RevokeUserTokens(r.Context(), deleted.ID)
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete user", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// handlerRestoreUser leaves chirps the user had deleted themselves deleted.
+func (cfg *apiConfig) handlerRestoreUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user id", err)
+		return
+	}
+
+	var user database.User
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		deleted, err := q.GetDeletedUserByID(r.Context(), userID)
+		if err != nil {
+			return err
+		}
+
+		err = q.RestoreUserChirps(r.Context(), database.RestoreUserChirpsParams{
+			UserID:    deleted.ID,
+			DeletedAt: deleted.DeletedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		user, err = q.RestoreUser(r.Context(), deleted.ID)
+		if err != nil {
+			return err
+		}
+
+		return q.RecountUserReactions(r.Context(), user.ID)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "No deleted user found", err)
+		return
+	}
+	if isUniqueViolation(err, "users_email_idx") {
+		respondWithError(w, http.StatusConflict, "Email is already registered to another account", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't restore user", err)
+		return
+	}
+
+	subscription, err := cfg.userSubscription(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve subscription", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, User{
+		ID:           user.ID,
+		CreatedAt:    user.CreatedAt,
+		UpdatedAt:    user.UpdatedAt,
+		Email:        user.Email,
+		Handle:       user.Handle.String,
+		IsChirpyRed:  user.IsChirpyRed,
+		Subscription: subscription,
+		Role:         user.Role,
+	})
+}
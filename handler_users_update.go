@@ -13,25 +13,22 @@ func (cfg *apiConfig) handlerUpdateUser(w http.ResponseWriter, r *http.Request)
 	type parameters struct {
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		Handle   string `json:"handle"`
 	}
 
-	token, err := auth.GetBearerToken(r.Header)
-	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Couldn't authorize token", err)
-		return
-	}
-
-	userID, err := auth.ValidateJWT(token, cfg.secret)
-	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Coudln't validate token", err)
-		return
-	}
+	currentUser := mustUser(r.Context())
 
 	decoder := json.NewDecoder(r.Body)
 	params := parameters{}
-	err = decoder.Decode(&params)
+	err := decoder.Decode(&params)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	handle, ok := parseHandle(w, params.Handle)
+	if !ok {
+		return
 	}
 
 	hashedPassword, err := auth.HashPassword(params.Password)
@@ -43,20 +40,37 @@ func (cfg *apiConfig) handlerUpdateUser(w http.ResponseWriter, r *http.Request)
 	user, err := cfg.db.UpdateUser(context.Background(), database.UpdateUserParams{
 		Email:          params.Email,
 		HashedPassword: hashedPassword,
-		ID:             userID,
+		ID:             currentUser.ID,
+		Handle:         handle,
 	})
-
+	if isUniqueViolation(err, "users_email_idx") {
+		respondWithError(w, http.StatusConflict, "Email is already registered", err)
+		return
+	}
+	if isUniqueViolation(err, "users_handle_idx") {
+		respondWithError(w, http.StatusConflict, "Handle is already taken", err)
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't update user", err)
 		return
 	}
 
+	subscription, err := cfg.userSubscription(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve subscription", err)
+		return
+	}
+
 	respondWithJSON(w, http.StatusOK, User{
-		ID:          user.ID,
-		CreatedAt:   user.CreatedAt,
-		UpdatedAt:   user.UpdatedAt,
-		Email:       user.Email,
-		IsChirpyRed: user.IsChirpyRed,
+		ID:           user.ID,
+		CreatedAt:    user.CreatedAt,
+		UpdatedAt:    user.UpdatedAt,
+		Email:        user.Email,
+		Handle:       user.Handle.String,
+		IsChirpyRed:  user.IsChirpyRed,
+		Subscription: subscription,
+		Role:         user.Role,
 	})
 
 }
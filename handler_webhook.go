@@ -2,18 +2,59 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/RafaelTauschek/http-server/internal/auth"
+	"github.com/RafaelTauschek/http-server/internal/database"
 	"github.com/google/uuid"
 )
 
+type subscriptionChange func(ctx context.Context, q *database.Queries, userID uuid.UUID, periodEnd sql.NullTime) (database.Subscription, error)
+
+// Other Polka events are acknowledged and ignored.
+var subscriptionEvents = map[string]subscriptionChange{
+	"user.upgraded": startSubscription,
+	"user.renewed":  startSubscription,
+	"user.canceled": func(ctx context.Context, q *database.Queries, userID uuid.UUID, periodEnd sql.NullTime) (database.Subscription, error) {
+		return q.CancelSubscription(ctx, database.CancelSubscriptionParams{
+			UserID:           userID,
+			CurrentPeriodEnd: periodEnd,
+		})
+	},
+	"user.downgraded": endSubscription(subscriptionDowngraded),
+	"user.refunded":   endSubscription(subscriptionRefunded),
+}
+
+var errUserDeleted = errors.New("user is deleted")
+
+func startSubscription(ctx context.Context, q *database.Queries, userID uuid.UUID, periodEnd sql.NullTime) (database.Subscription, error) {
+	return q.StartSubscription(ctx, database.StartSubscriptionParams{
+		UserID:           userID,
+		CurrentPeriodEnd: periodEnd,
+	})
+}
+
+func endSubscription(status string) subscriptionChange {
+	return func(ctx context.Context, q *database.Queries, userID uuid.UUID, _ sql.NullTime) (database.Subscription, error) {
+		return q.EndSubscription(ctx, database.EndSubscriptionParams{
+			UserID: userID,
+			Status: status,
+		})
+	}
+}
+
 func (cfg *apiConfig) handlerWebhook(w http.ResponseWriter, r *http.Request) {
 	type parameter struct {
 		Event string `json:"event"`
 		Data  struct {
 			UserID uuid.UUID `json:"user_id"`
+			// ExpiresAt is the end of the paid period. Subscriptions
+			// without one last until they are downgraded or refunded.
+			ExpiresAt *time.Time `json:"expires_at"`
 		}
 	}
 
@@ -37,17 +78,55 @@ func (cfg *apiConfig) handlerWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if params.Event != "user.upgraded" {
+	apply, ok := subscriptionEvents[params.Event]
+	if !ok {
 		respondWithJSON(w, http.StatusNoContent, nil)
 		return
 	}
 
-	_, err = cfg.db.UpgradeUser(context.Background(), params.Data.UserID)
+	periodEnd := sql.NullTime{}
+	if params.Data.ExpiresAt != nil {
+		periodEnd = sql.NullTime{Time: params.Data.ExpiresAt.UTC(), Valid: true}
+	}
 
-	if err != nil {
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		user, err := q.GetUserByID(r.Context(), params.Data.UserID)
+		if errors.Is(err, sql.ErrNoRows) {
+			if _, err := q.GetDeletedUserByID(r.Context(), params.Data.UserID); err == nil {
+				return errUserDeleted
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		sub, err := apply(r.Context(), q, user.ID, periodEnd)
+		// Polka retries until it gets a success.
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = q.SetUserChirpyRed(r.Context(), database.SetUserChirpyRedParams{
+			ID:          user.ID,
+			IsChirpyRed: grantsChirpyRed(sub.Status),
+		})
+		return err
+	})
+	if errors.Is(err, errUserDeleted) {
+		respondWithJSON(w, http.StatusNoContent, nil)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
 		respondWithError(w, http.StatusNotFound, "Couldn't find user", err)
 		return
 	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update subscription", err)
+		return
+	}
 
 	respondWithJSON(w, http.StatusNoContent, nil)
 }
@@ -33,17 +33,27 @@ func CheckPasswordHash(password, hash string) error {
 	return nil
 }
 
-func MakeJWT(userID uuid.UUID, tokenSecret string) (string, error) {
+const issuer = "chirpy"
+
+// Claims are the claims of a Chirpy access token. Role lets other services
+// authorize requests without looking the user up.
+type Claims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role,omitempty"`
+}
+
+func MakeJWT(userID uuid.UUID, role string, keys *Keyring, expiresIn time.Duration) (string, error) {
 	currentTime := time.Now().UTC()
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
-		Issuer:    "chirpy",
-		IssuedAt:  jwt.NewNumericDate(currentTime),
-		ExpiresAt: jwt.NewNumericDate(currentTime.Add(time.Duration(time.Hour))),
-		Subject:   userID.String(),
+	signedToken, err := keys.Sign(Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			IssuedAt:  jwt.NewNumericDate(currentTime),
+			ExpiresAt: jwt.NewNumericDate(currentTime.Add(expiresIn)),
+			Subject:   userID.String(),
+		},
+		Role: role,
 	})
-
-	signedToken, err := token.SignedString([]byte(tokenSecret))
 	if err != nil {
 		return "", err
 	}
@@ -51,24 +61,32 @@ func MakeJWT(userID uuid.UUID, tokenSecret string) (string, error) {
 	return signedToken, nil
 }
 
-func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
-	claims := &jwt.RegisteredClaims{}
+func ParseJWT(tokenString string, keys *Keyring) (*Claims, error) {
+	claims := &Claims{}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(tokenSecret), nil
-	})
+	_, err := keys.Parse(tokenString, claims,
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
 
+func ValidateJWT(tokenString string, keys *Keyring) (uuid.UUID, error) {
+	claims, err := ParseJWT(tokenString, keys)
 	if err != nil {
 		return uuid.Nil, err
 	}
 
-	subject, err := token.Claims.GetSubject()
+	id, err := uuid.Parse(claims.Subject)
 	if err != nil {
 		return uuid.Nil, err
 	}
-	uuid := uuid.MustParse(subject)
 
-	return uuid, nil
+	return id, nil
 }
 
 func GetBearerToken(headers http.Header) (string, error) {
@@ -3,6 +3,7 @@ package auth
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -70,8 +71,8 @@ func TestGetBearerToken(t *testing.T) {
 }
 
 func TestValidateJWT(t *testing.T) {
-	secret := "test-secret"
-	wrongSecret := "wrong-secret"
+	keys := newTestKeyring(t, NewHMACKey([]byte("test-secret")))
+	wrongKeys := newTestKeyring(t, NewHMACKey([]byte("wrong-secret")))
 	userID := uuid.New()
 
 	tests := []struct {
@@ -83,7 +84,7 @@ func TestValidateJWT(t *testing.T) {
 		{
 			name: "valid token",
 			setupToken: func() string {
-				token, err := MakeJWT(userID, secret)
+				token, err := MakeJWT(userID, "user", keys, time.Hour)
 				if err != nil {
 					t.Fatalf("failed to create test token: %v", err)
 				}
@@ -95,7 +96,7 @@ func TestValidateJWT(t *testing.T) {
 		{
 			name: "expired token",
 			setupToken: func() string {
-				token, err := MakeJWT(userID, secret)
+				token, err := MakeJWT(userID, "user", keys, -time.Minute)
 				if err != nil {
 					t.Fatalf("failed to create test token: %v", err)
 				}
@@ -107,7 +108,7 @@ func TestValidateJWT(t *testing.T) {
 		{
 			name: "wrong secret",
 			setupToken: func() string {
-				token, err := MakeJWT(userID, wrongSecret)
+				token, err := MakeJWT(userID, "user", wrongKeys, time.Hour)
 				if err != nil {
 					t.Fatalf("failed to create test token: %v", err)
 				}
@@ -128,7 +129,7 @@ func TestValidateJWT(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			id, err := ValidateJWT(tt.setupToken(), secret)
+			id, err := ValidateJWT(tt.setupToken(), keys)
 
 			if tt.expectedError && err == nil {
 				t.Error("expected error but got none")
@@ -144,3 +145,35 @@ func TestValidateJWT(t *testing.T) {
 		})
 	}
 }
+
+func newTestKeyring(t *testing.T, signing Key, verification ...Key) *Keyring {
+	t.Helper()
+	keys, err := NewKeyring(signing, verification...)
+	if err != nil {
+		t.Fatalf("failed to create keyring: %v", err)
+	}
+	return keys
+}
+
+func TestParseJWTRole(t *testing.T) {
+	keys := newTestKeyring(t, NewHMACKey([]byte("test-secret")))
+	userID := uuid.New()
+
+	token, err := MakeJWT(userID, "moderator", keys, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create test token: %v", err)
+	}
+
+	claims, err := ParseJWT(token, keys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if claims.Role != "moderator" {
+		t.Errorf("got role %q, want %q", claims.Role, "moderator")
+	}
+
+	if claims.Subject != userID.String() {
+		t.Errorf("got subject %q, want %q", claims.Subject, userID.String())
+	}
+}
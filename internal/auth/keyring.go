@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key is a JWT signing or verification key. The algorithm is fixed per key
+// so a token can never pick how it is verified.
+type Key struct {
+	ID        string
+	Algorithm string
+	sign      crypto.PrivateKey
+	verify    crypto.PublicKey
+}
+
+func (k Key) method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// NewHMACKey derives the key ID from the secret so that changing the secret
+// also changes the ID.
+func NewHMACKey(secret []byte) Key {
+	sum := sha256.Sum256(append([]byte("chirpy-kid:"), secret...))
+	return Key{
+		ID:        "hs256-" + hex.EncodeToString(sum[:8]),
+		Algorithm: jwt.SigningMethodHS256.Alg(),
+		sign:      secret,
+		verify:    secret,
+	}
+}
+
+func NewRSAKey(priv *rsa.PrivateKey) (Key, error) {
+	key, err := NewRSAPublicKey(&priv.PublicKey)
+	if err != nil {
+		return Key{}, err
+	}
+	key.sign = priv
+	return key, nil
+}
+
+func NewRSAPublicKey(pub *rsa.PublicKey) (Key, error) {
+	if pub.N.BitLen() < 2048 {
+		return Key{}, errors.New("RSA keys must be at least 2048 bits")
+	}
+
+	key := Key{Algorithm: jwt.SigningMethodRS256.Alg(), verify: pub}
+	key.ID = key.jwk().thumbprint()
+	return key, nil
+}
+
+func NewEd25519Key(priv ed25519.PrivateKey) Key {
+	key := NewEd25519PublicKey(priv.Public().(ed25519.PublicKey))
+	key.sign = priv
+	return key
+}
+
+func NewEd25519PublicKey(pub ed25519.PublicKey) Key {
+	key := Key{Algorithm: jwt.SigningMethodEdDSA.Alg(), verify: pub}
+	key.ID = key.jwk().thumbprint()
+	return key
+}
+
+// ParseKeyPEM reads a PKCS#8 private key or a PKIX public key holding an
+// RSA or Ed25519 key. Public keys can only be used for verification.
+func ParseKeyPEM(data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return Key{}, err
+		}
+		switch priv := parsed.(type) {
+		case *rsa.PrivateKey:
+			return NewRSAKey(priv)
+		case ed25519.PrivateKey:
+			return NewEd25519Key(priv), nil
+		}
+		return Key{}, fmt.Errorf("unsupported private key type %T", parsed)
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return Key{}, err
+		}
+		switch pub := parsed.(type) {
+		case *rsa.PublicKey:
+			return NewRSAPublicKey(pub)
+		case ed25519.PublicKey:
+			return NewEd25519PublicKey(pub), nil
+		}
+		return Key{}, fmt.Errorf("unsupported public key type %T", parsed)
+	}
+
+	return Key{}, fmt.Errorf("unsupported PEM block %q", block.Type)
+}
+
+// Keyring signs with a single key and verifies with any key it holds, which
+// lets old keys keep validating tokens while a new one is rolled out.
+type Keyring struct {
+	signing Key
+	keys    map[string]Key
+}
+
+func NewKeyring(signing Key, verification ...Key) (*Keyring, error) {
+	if signing.sign == nil {
+		return nil, errors.New("signing key has no private part")
+	}
+
+	k := &Keyring{signing: signing, keys: map[string]Key{signing.ID: signing}}
+	for _, key := range verification {
+		if existing, ok := k.keys[key.ID]; ok && existing.Algorithm != key.Algorithm {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		k.keys[key.ID] = key
+	}
+
+	return k, nil
+}
+
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signing.method(), claims)
+	token.Header["kid"] = k.signing.ID
+	return token.SignedString(k.signing.sign)
+}
+
+// Parse verifies tokenString with the key named by its kid header, only
+// accepting the algorithm that key was registered with.
+func (k *Keyring) Parse(tokenString string, claims jwt.Claims, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, errors.New("token has no kid header")
+		}
+
+		key, ok := k.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("key %q does not accept %s", kid, token.Method.Alg())
+		}
+
+		return key.verify, nil
+	}
+
+	return jwt.ParseWithClaims(tokenString, claims, keyFunc, opts...)
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS lists the public keys of the keyring. HMAC keys are shared secrets
+// and never published.
+func (k *Keyring) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range k.keys {
+		if key.Algorithm == jwt.SigningMethodHS256.Alg() {
+			continue
+		}
+		jwk := key.jwk()
+		jwk.Kid = key.ID
+		jwk.Use = "sig"
+		jwk.Alg = key.Algorithm
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+	return set
+}
+
+func (k Key) jwk() JWK {
+	switch pub := k.verify.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}
+	}
+	return JWK{}
+}
+
+// thumbprint is the RFC 7638 JWK thumbprint, used as a stable key ID.
+func (j JWK) thumbprint() string {
+	var members interface{}
+	switch j.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{j.E, j.Kty, j.N}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{j.Crv, j.Kty, j.X}
+	}
+
+	dat, _ := json.Marshal(members)
+	sum := sha256.Sum256(dat)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
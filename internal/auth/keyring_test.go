@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestKeyringValidateJWT(t *testing.T) {
+	rsaPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	rsaKey, err := NewRSAKey(rsaPriv)
+	if err != nil {
+		t.Fatalf("failed to create RSA key: %v", err)
+	}
+
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+	edKey := NewEd25519Key(edPriv)
+
+	hmacKey := NewHMACKey([]byte("test-secret"))
+	userID := uuid.New()
+
+	// The server verifies with every key but currently signs with EdDSA.
+	verifier := newTestKeyring(t, edKey, rsaKey, hmacKey)
+
+	tests := []struct {
+		name          string
+		setupToken    func() string
+		expectedError bool
+	}{
+		{
+			name: "EdDSA signing key",
+			setupToken: func() string {
+				token, err := MakeJWT(userID, "user", verifier, time.Hour)
+				if err != nil {
+					t.Fatalf("failed to create test token: %v", err)
+				}
+				return token
+			},
+			expectedError: false,
+		},
+		{
+			name: "previous RS256 key",
+			setupToken: func() string {
+				token, err := MakeJWT(userID, "user", newTestKeyring(t, rsaKey), time.Hour)
+				if err != nil {
+					t.Fatalf("failed to create test token: %v", err)
+				}
+				return token
+			},
+			expectedError: false,
+		},
+		{
+			name: "previous HS256 key",
+			setupToken: func() string {
+				token, err := MakeJWT(userID, "user", newTestKeyring(t, hmacKey), time.Hour)
+				if err != nil {
+					t.Fatalf("failed to create test token: %v", err)
+				}
+				return token
+			},
+			expectedError: false,
+		},
+		{
+			name: "unknown key id",
+			setupToken: func() string {
+				token, err := MakeJWT(userID, "user", newTestKeyring(t, NewHMACKey([]byte("other"))), time.Hour)
+				if err != nil {
+					t.Fatalf("failed to create test token: %v", err)
+				}
+				return token
+			},
+			expectedError: true,
+		},
+		{
+			name: "missing key id",
+			setupToken: func() string {
+				token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+					Issuer:    issuer,
+					Subject:   userID.String(),
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				}).SignedString([]byte("test-secret"))
+				if err != nil {
+					t.Fatalf("failed to create test token: %v", err)
+				}
+				return token
+			},
+			expectedError: true,
+		},
+		{
+			name: "algorithm does not match key",
+			setupToken: func() string {
+				// HS256 keyed with the RSA key id, the classic algorithm confusion attack.
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+					Issuer:    issuer,
+					Subject:   userID.String(),
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				})
+				token.Header["kid"] = rsaKey.ID
+				signed, err := token.SignedString(rsaPriv.PublicKey.N.Bytes())
+				if err != nil {
+					t.Fatalf("failed to create test token: %v", err)
+				}
+				return signed
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := ValidateJWT(tt.setupToken(), verifier)
+
+			if tt.expectedError && err == nil {
+				t.Error("expected error but got none")
+			}
+
+			if !tt.expectedError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !tt.expectedError && id != userID {
+				t.Errorf("got id %v, but expected %v", id, userID)
+			}
+		})
+	}
+}
+
+func TestKeyringJWKS(t *testing.T) {
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+	edKey := NewEd25519Key(edPriv)
+
+	keys := newTestKeyring(t, edKey, NewHMACKey([]byte("test-secret")))
+	set := keys.JWKS()
+
+	if len(set.Keys) != 1 {
+		t.Fatalf("got %d keys, want only the Ed25519 key", len(set.Keys))
+	}
+
+	if set.Keys[0].Kid != edKey.ID || set.Keys[0].Alg != "EdDSA" || set.Keys[0].Kty != "OKP" {
+		t.Errorf("unexpected JWK %+v", set.Keys[0])
+	}
+}
@@ -0,0 +1,46 @@
+// Package blob stores opaque files such as uploaded media.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// Store keeps blobs under slash separated keys. Implementations must be
+// safe for concurrent use; an object store lets several instances share
+// the same blobs.
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Get returns ErrNotFound when no blob is stored under key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete does nothing when no blob is stored under key.
+	Delete(ctx context.Context, key string) error
+}
+
+// ValidKey reports whether key is made of non-empty segments of letters,
+// digits, '-', '_' and '.', none of which are "." or "..".
+func ValidKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+		for _, r := range segment {
+			ok := r == '-' || r == '_' || r == '.' ||
+				(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+			if !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFS(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = store.Put(ctx, "attachments/abc.png", strings.NewReader("image data"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	rc, err := store.Get(ctx, "attachments/abc.png")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	dat, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil || string(dat) != "image data" {
+		t.Errorf("Get() = %q, %v, want %q", dat, err, "image data")
+	}
+
+	err = store.Delete(ctx, "attachments/abc.png")
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	_, err = store.Get(ctx, "attachments/abc.png")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want %v", err, ErrNotFound)
+	}
+
+	err = store.Delete(ctx, "attachments/abc.png")
+	if err != nil {
+		t.Errorf("Delete() of a missing blob error = %v, want nil", err)
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected bool
+	}{
+		{"attachments/abc.png", true},
+		{"a", true},
+		{"", false},
+		{"../etc/passwd", false},
+		{"a/../b", false},
+		{"/abs", false},
+		{"a//b", false},
+		{`a\b`, false},
+		{"a b", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidKey(tt.key); got != tt.expected {
+			t.Errorf("ValidKey(%q) = %v, want %v", tt.key, got, tt.expected)
+		}
+	}
+}
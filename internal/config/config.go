@@ -0,0 +1,356 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const MinSecretLength = 32
+
+var allowedPlatforms = []string{"dev", "prod"}
+
+type Config struct {
+	DBURL    string `yaml:"db_url"`
+	Platform string `yaml:"platform"`
+	Secret   string `yaml:"secret"`
+	PolkaKey string `yaml:"polka_key"`
+	// MetricsToken is the bearer token scrapers send to /metrics. When it
+	// is empty only admins can read the endpoint.
+	MetricsToken  string        `yaml:"metrics_token"`
+	Server        Server        `yaml:"server"`
+	JWT           JWT           `yaml:"jwt"`
+	Deletion      Deletion      `yaml:"deletion"`
+	Media         Media         `yaml:"media"`
+	Subscriptions Subscriptions `yaml:"subscriptions"`
+	// AdminEmails are given the admin role when the server starts, if an
+	// account with that email exists by then.
+	AdminEmails []string `yaml:"admin_emails"`
+	// WordListFile holds moderation rules in addition to the ones managed
+	// through the admin API.
+	WordListFile string `yaml:"wordlist_file"`
+}
+
+type Server struct {
+	Addr              string        `yaml:"addr"`
+	TrustProxyHeaders bool          `yaml:"trust_proxy_headers"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+}
+
+// JWT configures access tokens. They are signed with SigningKeyFile, or
+// with SECRET when it is empty, and verified with the signing key, SECRET
+// and every key in VerificationKeyFiles.
+type JWT struct {
+	SigningKeyFile       string        `yaml:"signing_key_file"`
+	VerificationKeyFiles []string      `yaml:"verification_key_files"`
+	AccessTokenTTL       time.Duration `yaml:"access_token_ttl"`
+}
+
+// Deletion controls soft deleted chirps and users. Authors can restore
+// them within RestoreWindow and they are purged for good once PurgeAfter
+// has passed. The purge job runs every PurgeInterval, or never when it is 0.
+type Deletion struct {
+	RestoreWindow time.Duration `yaml:"restore_window"`
+	PurgeAfter    time.Duration `yaml:"purge_after"`
+	PurgeInterval time.Duration `yaml:"purge_interval"`
+}
+
+// Media configures uploaded attachments. They are stored as files below
+// Dir and rejected when larger than MaxUploadBytes.
+type Media struct {
+	Dir            string `yaml:"dir"`
+	MaxUploadBytes int64  `yaml:"max_upload_bytes"`
+}
+
+// Subscriptions configures Chirpy Red billing. Subscriptions past the end
+// of their paid period are expired every ExpiryInterval, or never when it
+// is 0.
+type Subscriptions struct {
+	ExpiryInterval time.Duration `yaml:"expiry_interval"`
+}
+
+// ValidationError collects every problem found while loading the
+// configuration so they can all be fixed in one go.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+func Default() Config {
+	return Config{
+		Platform: "prod",
+		Server: Server{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      15 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+		},
+		JWT: JWT{
+			AccessTokenTTL: time.Hour,
+		},
+		Deletion: Deletion{
+			RestoreWindow: 7 * 24 * time.Hour,
+			PurgeAfter:    30 * 24 * time.Hour,
+			PurgeInterval: time.Hour,
+		},
+		Media: Media{
+			Dir:            "media",
+			MaxUploadBytes: 5 << 20,
+		},
+		Subscriptions: Subscriptions{
+			ExpiryInterval: time.Minute,
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the optional YAML file
+// named by CONFIG_FILE and the environment (including .env), in that order
+// of precedence.
+func Load() (Config, error) {
+	err := godotenv.Load()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Config{}, fmt.Errorf("couldn't load .env: %w", err)
+	}
+
+	cfg := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		err := loadFile(path, &cfg)
+		if err != nil {
+			return Config{}, err
+		}
+	}
+
+	problems := applyEnv(&cfg)
+	problems = append(problems, cfg.validate()...)
+
+	if len(problems) > 0 {
+		return Config{}, &ValidationError{Problems: problems}
+	}
+
+	return cfg, nil
+}
+
+func loadFile(path string, cfg *Config) error {
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("couldn't read config file: %w", err)
+	}
+
+	err = yaml.Unmarshal(dat, cfg)
+	if err != nil {
+		return fmt.Errorf("couldn't parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func applyEnv(cfg *Config) []string {
+	var problems []string
+
+	values := []struct {
+		key string
+		dst *string
+	}{
+		{"DB_URL", &cfg.DBURL},
+		{"PLATFORM", &cfg.Platform},
+		{"SECRET", &cfg.Secret},
+		{"POLKA_KEY", &cfg.PolkaKey},
+		{"METRICS_TOKEN", &cfg.MetricsToken},
+		{"ADDR", &cfg.Server.Addr},
+		{"JWT_SIGNING_KEY_FILE", &cfg.JWT.SigningKeyFile},
+		{"MODERATION_WORDLIST_FILE", &cfg.WordListFile},
+		{"MEDIA_DIR", &cfg.Media.Dir},
+	}
+
+	for _, v := range values {
+		if val, ok := os.LookupEnv(v.key); ok {
+			*v.dst = val
+		}
+	}
+
+	lists := []struct {
+		key string
+		dst *[]string
+	}{
+		{"JWT_VERIFICATION_KEY_FILES", &cfg.JWT.VerificationKeyFiles},
+		{"ADMIN_EMAILS", &cfg.AdminEmails},
+	}
+
+	for _, l := range lists {
+		if val, ok := os.LookupEnv(l.key); ok {
+			*l.dst = splitList(val)
+		}
+	}
+
+	if val, ok := os.LookupEnv("TRUST_PROXY_HEADERS"); ok {
+		parsed, err := strconv.ParseBool(val)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("TRUST_PROXY_HEADERS: %q is not a valid boolean", val))
+		} else {
+			cfg.Server.TrustProxyHeaders = parsed
+		}
+	}
+
+	if val, ok := os.LookupEnv("MEDIA_MAX_UPLOAD_BYTES"); ok {
+		parsed, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("MEDIA_MAX_UPLOAD_BYTES: %q is not a valid integer", val))
+		} else {
+			cfg.Media.MaxUploadBytes = parsed
+		}
+	}
+
+	durations := []struct {
+		key string
+		dst *time.Duration
+	}{
+		{"READ_HEADER_TIMEOUT", &cfg.Server.ReadHeaderTimeout},
+		{"READ_TIMEOUT", &cfg.Server.ReadTimeout},
+		{"WRITE_TIMEOUT", &cfg.Server.WriteTimeout},
+		{"IDLE_TIMEOUT", &cfg.Server.IdleTimeout},
+		{"SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout},
+		{"ACCESS_TOKEN_TTL", &cfg.JWT.AccessTokenTTL},
+		{"RESTORE_WINDOW", &cfg.Deletion.RestoreWindow},
+		{"PURGE_AFTER", &cfg.Deletion.PurgeAfter},
+		{"PURGE_INTERVAL", &cfg.Deletion.PurgeInterval},
+		{"SUBSCRIPTION_EXPIRY_INTERVAL", &cfg.Subscriptions.ExpiryInterval},
+	}
+
+	for _, d := range durations {
+		val, ok := os.LookupEnv(d.key)
+		if !ok {
+			continue
+		}
+
+		parsed, err := time.ParseDuration(val)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %q is not a valid duration", d.key, val))
+			continue
+		}
+		*d.dst = parsed
+	}
+
+	return problems
+}
+
+func splitList(val string) []string {
+	var items []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (cfg Config) validate() []string {
+	var problems []string
+
+	if cfg.DBURL == "" {
+		problems = append(problems, "DB_URL is required")
+	} else if u, err := url.Parse(cfg.DBURL); err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		problems = append(problems, "DB_URL must be a postgres:// URL")
+	}
+
+	if !isAllowedPlatform(cfg.Platform) {
+		problems = append(problems, fmt.Sprintf("PLATFORM must be one of %s, got %q", strings.Join(allowedPlatforms, ", "), cfg.Platform))
+	}
+
+	if len(cfg.Secret) < MinSecretLength {
+		problems = append(problems, fmt.Sprintf("SECRET must be at least %d characters long", MinSecretLength))
+	}
+
+	if cfg.PolkaKey == "" {
+		problems = append(problems, "POLKA_KEY is required")
+	}
+
+	if cfg.Server.Addr == "" {
+		problems = append(problems, "ADDR must not be empty")
+	}
+
+	timeouts := []struct {
+		key string
+		val time.Duration
+	}{
+		{"READ_HEADER_TIMEOUT", cfg.Server.ReadHeaderTimeout},
+		{"READ_TIMEOUT", cfg.Server.ReadTimeout},
+		{"WRITE_TIMEOUT", cfg.Server.WriteTimeout},
+		{"IDLE_TIMEOUT", cfg.Server.IdleTimeout},
+		{"SHUTDOWN_TIMEOUT", cfg.Server.ShutdownTimeout},
+	}
+
+	for _, t := range timeouts {
+		if t.val < 0 {
+			problems = append(problems, t.key+" must not be negative")
+		}
+	}
+
+	if cfg.JWT.AccessTokenTTL <= 0 {
+		problems = append(problems, "ACCESS_TOKEN_TTL must be positive")
+	}
+
+	if cfg.Deletion.RestoreWindow < 0 {
+		problems = append(problems, "RESTORE_WINDOW must not be negative")
+	}
+	if cfg.Deletion.PurgeAfter < cfg.Deletion.RestoreWindow {
+		problems = append(problems, "PURGE_AFTER must not be shorter than RESTORE_WINDOW")
+	}
+	if cfg.Deletion.PurgeInterval < 0 {
+		problems = append(problems, "PURGE_INTERVAL must not be negative")
+	}
+
+	if cfg.Media.Dir == "" {
+		problems = append(problems, "MEDIA_DIR must not be empty")
+	}
+	if cfg.Media.MaxUploadBytes <= 0 {
+		problems = append(problems, "MEDIA_MAX_UPLOAD_BYTES must be positive")
+	}
+
+	if cfg.Subscriptions.ExpiryInterval < 0 {
+		problems = append(problems, "SUBSCRIPTION_EXPIRY_INTERVAL must not be negative")
+	}
+
+	keyFiles := cfg.JWT.VerificationKeyFiles
+	if cfg.JWT.SigningKeyFile != "" {
+		keyFiles = append([]string{cfg.JWT.SigningKeyFile}, keyFiles...)
+	}
+	for _, path := range keyFiles {
+		if _, err := os.Stat(path); err != nil {
+			problems = append(problems, fmt.Sprintf("JWT key file %s is not readable: %v", path, err))
+		}
+	}
+
+	if cfg.WordListFile != "" {
+		if _, err := os.Stat(cfg.WordListFile); err != nil {
+			problems = append(problems, fmt.Sprintf("MODERATION_WORDLIST_FILE %s is not readable: %v", cfg.WordListFile, err))
+		}
+	}
+
+	return problems
+}
+
+func isAllowedPlatform(platform string) bool {
+	for _, p := range allowedPlatforms {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: attachments.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const countChirpAttachments = `-- name: CountChirpAttachments :one
+SELECT COUNT(*) FROM attachments
+WHERE chirp_id = $1
+`
+
+func (q *Queries) CountChirpAttachments(ctx context.Context, chirpID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countChirpAttachments, chirpID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAttachment = `-- name: CreateAttachment :one
+INSERT INTO attachments (
+    id, created_at, chirp_id, content_type, size_bytes, width, height, blob_key,
+    thumbnail_content_type, thumbnail_width, thumbnail_height, thumbnail_key
+)
+VALUES ($1, NOW(), $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, created_at, chirp_id, content_type, size_bytes, width, height, blob_key, thumbnail_content_type, thumbnail_width, thumbnail_height, thumbnail_key
+`
+
+type CreateAttachmentParams struct {
+	ID                   uuid.UUID
+	ChirpID              uuid.UUID
+	ContentType          string
+	SizeBytes            int64
+	Width                int32
+	Height               int32
+	BlobKey              string
+	ThumbnailContentType string
+	ThumbnailWidth       int32
+	ThumbnailHeight      int32
+	ThumbnailKey         string
+}
+
+func (q *Queries) CreateAttachment(ctx context.Context, arg CreateAttachmentParams) (Attachment, error) {
+	row := q.db.QueryRowContext(ctx, createAttachment,
+		arg.ID,
+		arg.ChirpID,
+		arg.ContentType,
+		arg.SizeBytes,
+		arg.Width,
+		arg.Height,
+		arg.BlobKey,
+		arg.ThumbnailContentType,
+		arg.ThumbnailWidth,
+		arg.ThumbnailHeight,
+		arg.ThumbnailKey,
+	)
+	var i Attachment
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ChirpID,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Width,
+		&i.Height,
+		&i.BlobKey,
+		&i.ThumbnailContentType,
+		&i.ThumbnailWidth,
+		&i.ThumbnailHeight,
+		&i.ThumbnailKey,
+	)
+	return i, err
+}
+
+const deleteAttachment = `-- name: DeleteAttachment :exec
+DELETE FROM attachments
+WHERE id = $1
+`
+
+func (q *Queries) DeleteAttachment(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteAttachment, id)
+	return err
+}
+
+const getAttachment = `-- name: GetAttachment :one
+SELECT id, created_at, chirp_id, content_type, size_bytes, width, height, blob_key, thumbnail_content_type, thumbnail_width, thumbnail_height, thumbnail_key FROM attachments
+WHERE id = $1
+`
+
+func (q *Queries) GetAttachment(ctx context.Context, id uuid.UUID) (Attachment, error) {
+	row := q.db.QueryRowContext(ctx, getAttachment, id)
+	var i Attachment
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ChirpID,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Width,
+		&i.Height,
+		&i.BlobKey,
+		&i.ThumbnailContentType,
+		&i.ThumbnailWidth,
+		&i.ThumbnailHeight,
+		&i.ThumbnailKey,
+	)
+	return i, err
+}
+
+const listChirpAttachments = `-- name: ListChirpAttachments :many
+SELECT id, created_at, chirp_id, content_type, size_bytes, width, height, blob_key, thumbnail_content_type, thumbnail_width, thumbnail_height, thumbnail_key FROM attachments
+WHERE chirp_id = ANY($1::uuid[])
+ORDER BY chirp_id, created_at, id
+`
+
+func (q *Queries) ListChirpAttachments(ctx context.Context, chirpIds []uuid.UUID) ([]Attachment, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpAttachments, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Attachment
+	for rows.Next() {
+		var i Attachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.ChirpID,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Width,
+			&i.Height,
+			&i.BlobKey,
+			&i.ThumbnailContentType,
+			&i.ThumbnailWidth,
+			&i.ThumbnailHeight,
+			&i.ThumbnailKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPurgeableAttachments = `-- name: ListPurgeableAttachments :many
+SELECT attachments.blob_key, attachments.thumbnail_key
+FROM attachments
+JOIN chirps ON chirps.id = attachments.chirp_id
+LEFT JOIN users ON users.id = chirps.user_id
+WHERE chirps.deleted_at < NOW() - $1::integer * interval '1 second'
+OR users.deleted_at < NOW() - $1::integer * interval '1 second'
+`
+
+type ListPurgeableAttachmentsRow struct {
+	BlobKey      string
+	ThumbnailKey string
+}
+
+func (q *Queries) ListPurgeableAttachments(ctx context.Context, purgeAfterSeconds int32) ([]ListPurgeableAttachmentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPurgeableAttachments, purgeAfterSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPurgeableAttachmentsRow
+	for rows.Next() {
+		var i ListPurgeableAttachmentsRow
+		if err := rows.Scan(&i.BlobKey, &i.ThumbnailKey); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -7,29 +7,34 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_id)
 VALUES(
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, hidden, deleted_at, deleted_by, like_count, rechirp_count, parent_id
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.UUID
+	Body     string
+	UserID   uuid.UUID
+	ParentID uuid.NullUUID
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID, arg.ParentID)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,18 +42,33 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.Hidden,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.LikeCount,
+		&i.RechirpCount,
+		&i.ParentID,
 	)
 	return i, err
 }
 
-const deleteChirp = `-- name: DeleteChirp :exec
-DELETE FROM chirps
-WHERE id = $1
+const deleteChirp = `-- name: DeleteChirp :execrows
+UPDATE chirps
+SET deleted_at = NOW(), deleted_by = $2
+WHERE id = $1 AND deleted_at IS NULL
 `
 
-func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteChirp, id)
-	return err
+type DeleteChirpParams struct {
+	ID        uuid.UUID
+	DeletedBy uuid.NullUUID
+}
+
+func (q *Queries) DeleteChirp(ctx context.Context, arg DeleteChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteChirp, arg.ID, arg.DeletedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const deleteChirps = `-- name: DeleteChirps :exec
@@ -60,8 +80,24 @@ func (q *Queries) DeleteChirps(ctx context.Context) error {
 	return err
 }
 
+const deleteUserChirps = `-- name: DeleteUserChirps :exec
+UPDATE chirps
+SET deleted_at = $2, deleted_by = user_id
+WHERE user_id = $1 AND deleted_at IS NULL
+`
+
+type DeleteUserChirpsParams struct {
+	UserID    uuid.UUID
+	DeletedAt sql.NullTime
+}
+
+func (q *Queries) DeleteUserChirps(ctx context.Context, arg DeleteUserChirpsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUserChirps, arg.UserID, arg.DeletedAt)
+	return err
+}
+
 const getChirpById = `-- name: GetChirpById :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps WHERE id = $1
+SELECT id, created_at, updated_at, body, user_id, hidden, deleted_at, deleted_by, like_count, rechirp_count, parent_id FROM chirps WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetChirpById(ctx context.Context, id uuid.UUID) (Chirp, error) {
@@ -73,18 +109,241 @@ func (q *Queries) GetChirpById(ctx context.Context, id uuid.UUID) (Chirp, error)
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.Hidden,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.LikeCount,
+		&i.RechirpCount,
+		&i.ParentID,
+	)
+	return i, err
+}
+
+const getChirpForUpdate = `-- name: GetChirpForUpdate :one
+SELECT id, created_at, updated_at, body, user_id, hidden, deleted_at, deleted_by, like_count, rechirp_count, parent_id FROM chirps
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE
+`
+
+func (q *Queries) GetChirpForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpForUpdate, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.Hidden,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.LikeCount,
+		&i.RechirpCount,
+		&i.ParentID,
 	)
 	return i, err
 }
 
-const getChirpsByUser = `-- name: GetChirpsByUser :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-WHERE user_id = $1
-ORDER BY created_at ASC
+const listChirpAncestors = `-- name: ListChirpAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden, chirps.deleted_at, chirps.deleted_by, chirps.like_count, chirps.rechirp_count, chirps.parent_id, 1 AS depth
+    FROM chirps
+    WHERE chirps.id = (SELECT c.parent_id FROM chirps c WHERE c.id = $1)
+    UNION ALL
+    SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden, chirps.deleted_at, chirps.deleted_by, chirps.like_count, chirps.rechirp_count, chirps.parent_id, ancestors.depth + 1
+    FROM chirps
+    JOIN ancestors ON chirps.id = ancestors.parent_id
+    WHERE ancestors.depth < $2::integer
+)
+SELECT id, created_at, updated_at, body, user_id, hidden, deleted_at, deleted_by, like_count, rechirp_count, parent_id, depth FROM ancestors
+ORDER BY depth DESC
+`
+
+type ListChirpAncestorsParams struct {
+	ID       uuid.UUID
+	MaxDepth int32
+}
+
+type ListChirpAncestorsRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Body         string
+	UserID       uuid.UUID
+	Hidden       bool
+	DeletedAt    sql.NullTime
+	DeletedBy    uuid.NullUUID
+	LikeCount    int32
+	RechirpCount int32
+	ParentID     uuid.NullUUID
+	Depth        int32
+}
+
+func (q *Queries) ListChirpAncestors(ctx context.Context, arg ListChirpAncestorsParams) ([]ListChirpAncestorsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpAncestors, arg.ID, arg.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListChirpAncestorsRow
+	for rows.Next() {
+		var i ListChirpAncestorsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.Hidden,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.LikeCount,
+			&i.RechirpCount,
+			&i.ParentID,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpDescendants = `-- name: ListChirpDescendants :many
+WITH RECURSIVE descendants AS (
+    SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden, chirps.deleted_at, chirps.deleted_by, chirps.like_count, chirps.rechirp_count, chirps.parent_id, 1 AS depth
+    FROM chirps
+    WHERE chirps.parent_id = $2::uuid
+    UNION ALL
+    SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden, chirps.deleted_at, chirps.deleted_by, chirps.like_count, chirps.rechirp_count, chirps.parent_id, descendants.depth + 1
+    FROM chirps
+    JOIN descendants ON chirps.parent_id = descendants.id
+    WHERE descendants.depth < $3::integer
+)
+SELECT id, created_at, updated_at, body, user_id, hidden, deleted_at, deleted_by, like_count, rechirp_count, parent_id, depth FROM descendants
+ORDER BY depth, created_at, id
+LIMIT $1
+`
+
+type ListChirpDescendantsParams struct {
+	Limit    int32
+	ID       uuid.UUID
+	MaxDepth int32
+}
+
+type ListChirpDescendantsRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Body         string
+	UserID       uuid.UUID
+	Hidden       bool
+	DeletedAt    sql.NullTime
+	DeletedBy    uuid.NullUUID
+	LikeCount    int32
+	RechirpCount int32
+	ParentID     uuid.NullUUID
+	Depth        int32
+}
+
+func (q *Queries) ListChirpDescendants(ctx context.Context, arg ListChirpDescendantsParams) ([]ListChirpDescendantsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpDescendants, arg.Limit, arg.ID, arg.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListChirpDescendantsRow
+	for rows.Next() {
+		var i ListChirpDescendantsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.Hidden,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.LikeCount,
+			&i.RechirpCount,
+			&i.ParentID,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsByCreatedAsc = `-- name: ListChirpsByCreatedAsc :many
+SELECT id, created_at, updated_at, body, user_id, hidden, deleted_at, deleted_by, like_count, rechirp_count, parent_id FROM chirps
+WHERE NOT hidden AND deleted_at IS NULL
+AND (COALESCE(cardinality($1::uuid[]), 0) = 0 OR user_id = ANY($1::uuid[]))
+AND ($2::timestamp IS NULL OR created_at > $2::timestamp)
+AND ($3::timestamp IS NULL OR created_at < $3::timestamp)
+AND ($4::text IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_hashtags
+    WHERE chirp_hashtags.chirp_id = chirps.id AND chirp_hashtags.tag = lower($4::text)
+))
+AND ($5::text IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_mentions
+    JOIN users ON users.id = chirp_mentions.user_id
+    WHERE chirp_mentions.chirp_id = chirps.id AND lower(users.handle) = lower($5::text)
+))
+AND ($6::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_mentions
+    WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = $6::uuid
+))
+AND ($7::uuid IS NULL OR user_id = $7::uuid OR user_id IN (
+    SELECT followee_id FROM follows WHERE follower_id = $7::uuid
+))
+AND (
+    $8::timestamp IS NULL
+    OR (created_at, id) > ($8::timestamp, $9::uuid)
+)
+ORDER BY created_at ASC, id ASC
+LIMIT $10
 `
 
-func (q *Queries) GetChirpsByUser(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsByUser, userID)
+type ListChirpsByCreatedAscParams struct {
+	AuthorIds       []uuid.UUID
+	CreatedAfter    sql.NullTime
+	CreatedBefore   sql.NullTime
+	Hashtag         sql.NullString
+	Mention         sql.NullString
+	MentionedUserID uuid.NullUUID
+	TimelineOf      uuid.NullUUID
+	CursorTime      sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) ListChirpsByCreatedAsc(ctx context.Context, arg ListChirpsByCreatedAscParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByCreatedAsc,
+		pq.Array(arg.AuthorIds),
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.Hashtag,
+		arg.Mention,
+		arg.MentionedUserID,
+		arg.TimelineOf,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -98,6 +357,12 @@ func (q *Queries) GetChirpsByUser(ctx context.Context, userID uuid.UUID) ([]Chir
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.Hidden,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.LikeCount,
+			&i.RechirpCount,
+			&i.ParentID,
 		); err != nil {
 			return nil, err
 		}
@@ -112,13 +377,62 @@ func (q *Queries) GetChirpsByUser(ctx context.Context, userID uuid.UUID) ([]Chir
 	return items, nil
 }
 
-const getChrips = `-- name: GetChrips :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-ORDER BY created_at ASC
+const listChirpsByCreatedDesc = `-- name: ListChirpsByCreatedDesc :many
+SELECT id, created_at, updated_at, body, user_id, hidden, deleted_at, deleted_by, like_count, rechirp_count, parent_id FROM chirps
+WHERE NOT hidden AND deleted_at IS NULL
+AND (COALESCE(cardinality($1::uuid[]), 0) = 0 OR user_id = ANY($1::uuid[]))
+AND ($2::timestamp IS NULL OR created_at > $2::timestamp)
+AND ($3::timestamp IS NULL OR created_at < $3::timestamp)
+AND ($4::text IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_hashtags
+    WHERE chirp_hashtags.chirp_id = chirps.id AND chirp_hashtags.tag = lower($4::text)
+))
+AND ($5::text IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_mentions
+    JOIN users ON users.id = chirp_mentions.user_id
+    WHERE chirp_mentions.chirp_id = chirps.id AND lower(users.handle) = lower($5::text)
+))
+AND ($6::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_mentions
+    WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = $6::uuid
+))
+AND ($7::uuid IS NULL OR user_id = $7::uuid OR user_id IN (
+    SELECT followee_id FROM follows WHERE follower_id = $7::uuid
+))
+AND (
+    $8::timestamp IS NULL
+    OR (created_at, id) < ($8::timestamp, $9::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $10
 `
 
-func (q *Queries) GetChrips(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChrips)
+type ListChirpsByCreatedDescParams struct {
+	AuthorIds       []uuid.UUID
+	CreatedAfter    sql.NullTime
+	CreatedBefore   sql.NullTime
+	Hashtag         sql.NullString
+	Mention         sql.NullString
+	MentionedUserID uuid.NullUUID
+	TimelineOf      uuid.NullUUID
+	CursorTime      sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) ListChirpsByCreatedDesc(ctx context.Context, arg ListChirpsByCreatedDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByCreatedDesc,
+		pq.Array(arg.AuthorIds),
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.Hashtag,
+		arg.Mention,
+		arg.MentionedUserID,
+		arg.TimelineOf,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -132,6 +446,12 @@ func (q *Queries) GetChrips(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.Hidden,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.LikeCount,
+			&i.RechirpCount,
+			&i.ParentID,
 		); err != nil {
 			return nil, err
 		}
@@ -145,3 +465,381 @@ func (q *Queries) GetChrips(ctx context.Context) ([]Chirp, error) {
 	}
 	return items, nil
 }
+
+const listChirpsByUpdatedAsc = `-- name: ListChirpsByUpdatedAsc :many
+SELECT id, created_at, updated_at, body, user_id, hidden, deleted_at, deleted_by, like_count, rechirp_count, parent_id FROM chirps
+WHERE NOT hidden AND deleted_at IS NULL
+AND (COALESCE(cardinality($1::uuid[]), 0) = 0 OR user_id = ANY($1::uuid[]))
+AND ($2::timestamp IS NULL OR created_at > $2::timestamp)
+AND ($3::timestamp IS NULL OR created_at < $3::timestamp)
+AND ($4::text IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_hashtags
+    WHERE chirp_hashtags.chirp_id = chirps.id AND chirp_hashtags.tag = lower($4::text)
+))
+AND ($5::text IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_mentions
+    JOIN users ON users.id = chirp_mentions.user_id
+    WHERE chirp_mentions.chirp_id = chirps.id AND lower(users.handle) = lower($5::text)
+))
+AND ($6::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_mentions
+    WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = $6::uuid
+))
+AND ($7::uuid IS NULL OR user_id = $7::uuid OR user_id IN (
+    SELECT followee_id FROM follows WHERE follower_id = $7::uuid
+))
+AND (
+    $8::timestamp IS NULL
+    OR (updated_at, id) > ($8::timestamp, $9::uuid)
+)
+ORDER BY updated_at ASC, id ASC
+LIMIT $10
+`
+
+type ListChirpsByUpdatedAscParams struct {
+	AuthorIds       []uuid.UUID
+	CreatedAfter    sql.NullTime
+	CreatedBefore   sql.NullTime
+	Hashtag         sql.NullString
+	Mention         sql.NullString
+	MentionedUserID uuid.NullUUID
+	TimelineOf      uuid.NullUUID
+	CursorTime      sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) ListChirpsByUpdatedAsc(ctx context.Context, arg ListChirpsByUpdatedAscParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByUpdatedAsc,
+		pq.Array(arg.AuthorIds),
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.Hashtag,
+		arg.Mention,
+		arg.MentionedUserID,
+		arg.TimelineOf,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.Hidden,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.LikeCount,
+			&i.RechirpCount,
+			&i.ParentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsByUpdatedDesc = `-- name: ListChirpsByUpdatedDesc :many
+SELECT id, created_at, updated_at, body, user_id, hidden, deleted_at, deleted_by, like_count, rechirp_count, parent_id FROM chirps
+WHERE NOT hidden AND deleted_at IS NULL
+AND (COALESCE(cardinality($1::uuid[]), 0) = 0 OR user_id = ANY($1::uuid[]))
+AND ($2::timestamp IS NULL OR created_at > $2::timestamp)
+AND ($3::timestamp IS NULL OR created_at < $3::timestamp)
+AND ($4::text IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_hashtags
+    WHERE chirp_hashtags.chirp_id = chirps.id AND chirp_hashtags.tag = lower($4::text)
+))
+AND ($5::text IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_mentions
+    JOIN users ON users.id = chirp_mentions.user_id
+    WHERE chirp_mentions.chirp_id = chirps.id AND lower(users.handle) = lower($5::text)
+))
+AND ($6::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_mentions
+    WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = $6::uuid
+))
+AND ($7::uuid IS NULL OR user_id = $7::uuid OR user_id IN (
+    SELECT followee_id FROM follows WHERE follower_id = $7::uuid
+))
+AND (
+    $8::timestamp IS NULL
+    OR (updated_at, id) < ($8::timestamp, $9::uuid)
+)
+ORDER BY updated_at DESC, id DESC
+LIMIT $10
+`
+
+type ListChirpsByUpdatedDescParams struct {
+	AuthorIds       []uuid.UUID
+	CreatedAfter    sql.NullTime
+	CreatedBefore   sql.NullTime
+	Hashtag         sql.NullString
+	Mention         sql.NullString
+	MentionedUserID uuid.NullUUID
+	TimelineOf      uuid.NullUUID
+	CursorTime      sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) ListChirpsByUpdatedDesc(ctx context.Context, arg ListChirpsByUpdatedDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByUpdatedDesc,
+		pq.Array(arg.AuthorIds),
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.Hashtag,
+		arg.Mention,
+		arg.MentionedUserID,
+		arg.TimelineOf,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.Hidden,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.LikeCount,
+			&i.RechirpCount,
+			&i.ParentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedChirps = `-- name: PurgeDeletedChirps :execrows
+DELETE FROM chirps
+WHERE deleted_at < NOW() - $1::integer * interval '1 second'
+`
+
+func (q *Queries) PurgeDeletedChirps(ctx context.Context, purgeAfterSeconds int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedChirps, purgeAfterSeconds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreChirp = `-- name: RestoreChirp :one
+UPDATE chirps
+SET deleted_at = NULL, deleted_by = NULL
+WHERE id = $1
+AND user_id = $2
+AND deleted_by = $2
+AND deleted_at > NOW() - $3::integer * interval '1 second'
+RETURNING id, created_at, updated_at, body, user_id, hidden, deleted_at, deleted_by, like_count, rechirp_count, parent_id
+`
+
+type RestoreChirpParams struct {
+	ID                   uuid.UUID
+	UserID               uuid.UUID
+	RestoreWindowSeconds int32
+}
+
+func (q *Queries) RestoreChirp(ctx context.Context, arg RestoreChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, restoreChirp, arg.ID, arg.UserID, arg.RestoreWindowSeconds)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.Hidden,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.LikeCount,
+		&i.RechirpCount,
+		&i.ParentID,
+	)
+	return i, err
+}
+
+const restoreUserChirps = `-- name: RestoreUserChirps :exec
+UPDATE chirps
+SET deleted_at = NULL, deleted_by = NULL
+WHERE user_id = $1 AND deleted_at = $2
+`
+
+type RestoreUserChirpsParams struct {
+	UserID    uuid.UUID
+	DeletedAt sql.NullTime
+}
+
+func (q *Queries) RestoreUserChirps(ctx context.Context, arg RestoreUserChirpsParams) error {
+	_, err := q.db.ExecContext(ctx, restoreUserChirps, arg.UserID, arg.DeletedAt)
+	return err
+}
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT
+    chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden, chirps.deleted_at, chirps.deleted_by, chirps.like_count, chirps.rechirp_count, chirps.parent_id,
+    ts_rank_cd(to_tsvector('english', chirps.body), query)::real AS rank,
+    ts_headline('english', translate(chirps.body, chr(2) || chr(3), ''), query, $1)::text AS snippet
+FROM chirps, to_tsquery('english', $2) query
+WHERE to_tsvector('english', chirps.body) @@ query
+AND NOT chirps.hidden AND chirps.deleted_at IS NULL
+AND ($3::uuid IS NULL OR chirps.user_id = $3::uuid)
+AND ($4::timestamp IS NULL OR chirps.created_at >= $4::timestamp)
+AND ($5::timestamp IS NULL OR chirps.created_at < $5::timestamp)
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT $7 OFFSET $6
+`
+
+type SearchChirpsParams struct {
+	HeadlineOptions string
+	Query           string
+	AuthorID        uuid.NullUUID
+	Since           sql.NullTime
+	Until           sql.NullTime
+	Offset          int32
+	Limit           int32
+}
+
+type SearchChirpsRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Body         string
+	UserID       uuid.UUID
+	Hidden       bool
+	DeletedAt    sql.NullTime
+	DeletedBy    uuid.NullUUID
+	LikeCount    int32
+	RechirpCount int32
+	ParentID     uuid.NullUUID
+	Rank         float32
+	Snippet      string
+}
+
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]SearchChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps,
+		arg.HeadlineOptions,
+		arg.Query,
+		arg.AuthorID,
+		arg.Since,
+		arg.Until,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsRow
+	for rows.Next() {
+		var i SearchChirpsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.Hidden,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.LikeCount,
+			&i.RechirpCount,
+			&i.ParentID,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setChirpHidden = `-- name: SetChirpHidden :execrows
+UPDATE chirps
+SET hidden = $2
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+type SetChirpHiddenParams struct {
+	ID     uuid.UUID
+	Hidden bool
+}
+
+func (q *Queries) SetChirpHidden(ctx context.Context, arg SetChirpHiddenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setChirpHidden, arg.ID, arg.Hidden)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, hidden, deleted_at, deleted_by, like_count, rechirp_count, parent_id
+`
+
+type UpdateChirpBodyParams struct {
+	ID   uuid.UUID
+	Body string
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.ID, arg.Body)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.Hidden,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.LikeCount,
+		&i.RechirpCount,
+		&i.ParentID,
+	)
+	return i, err
+}
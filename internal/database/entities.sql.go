@@ -0,0 +1,187 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: entities.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createChirpHashtag = `-- name: CreateChirpHashtag :exec
+INSERT INTO chirp_hashtags (chirp_id, tag, start_offset, end_offset)
+VALUES ($1, $2, $3, $4)
+`
+
+type CreateChirpHashtagParams struct {
+	ChirpID     uuid.UUID
+	Tag         string
+	StartOffset int32
+	EndOffset   int32
+}
+
+func (q *Queries) CreateChirpHashtag(ctx context.Context, arg CreateChirpHashtagParams) error {
+	_, err := q.db.ExecContext(ctx, createChirpHashtag,
+		arg.ChirpID,
+		arg.Tag,
+		arg.StartOffset,
+		arg.EndOffset,
+	)
+	return err
+}
+
+const createChirpMention = `-- name: CreateChirpMention :exec
+INSERT INTO chirp_mentions (chirp_id, user_id, start_offset, end_offset)
+VALUES ($1, $2, $3, $4)
+`
+
+type CreateChirpMentionParams struct {
+	ChirpID     uuid.UUID
+	UserID      uuid.UUID
+	StartOffset int32
+	EndOffset   int32
+}
+
+func (q *Queries) CreateChirpMention(ctx context.Context, arg CreateChirpMentionParams) error {
+	_, err := q.db.ExecContext(ctx, createChirpMention,
+		arg.ChirpID,
+		arg.UserID,
+		arg.StartOffset,
+		arg.EndOffset,
+	)
+	return err
+}
+
+const deleteChirpEntities = `-- name: DeleteChirpEntities :exec
+WITH deleted_hashtags AS (
+    DELETE FROM chirp_hashtags WHERE chirp_hashtags.chirp_id = $1
+)
+DELETE FROM chirp_mentions WHERE chirp_mentions.chirp_id = $1
+`
+
+func (q *Queries) DeleteChirpEntities(ctx context.Context, chirpID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteChirpEntities, chirpID)
+	return err
+}
+
+const getUsersByHandles = `-- name: GetUsersByHandles :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deleted_at, handle FROM users
+WHERE lower(handle) = ANY($1::text[]) AND deleted_at IS NULL
+`
+
+func (q *Queries) GetUsersByHandles(ctx context.Context, handles []string) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByHandles, pq.Array(handles))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.Role,
+			&i.DeletedAt,
+			&i.Handle,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpHashtags = `-- name: ListChirpHashtags :many
+SELECT chirp_id, tag, start_offset, end_offset FROM chirp_hashtags
+WHERE chirp_id = ANY($1::uuid[])
+ORDER BY chirp_id, start_offset
+`
+
+func (q *Queries) ListChirpHashtags(ctx context.Context, chirpIds []uuid.UUID) ([]ChirpHashtag, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpHashtags, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChirpHashtag
+	for rows.Next() {
+		var i ChirpHashtag
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.Tag,
+			&i.StartOffset,
+			&i.EndOffset,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpMentions = `-- name: ListChirpMentions :many
+SELECT chirp_mentions.chirp_id, chirp_mentions.user_id, chirp_mentions.start_offset, chirp_mentions.end_offset, users.handle
+FROM chirp_mentions
+JOIN users ON users.id = chirp_mentions.user_id
+WHERE chirp_mentions.chirp_id = ANY($1::uuid[])
+ORDER BY chirp_mentions.chirp_id, chirp_mentions.start_offset
+`
+
+type ListChirpMentionsRow struct {
+	ChirpID     uuid.UUID
+	UserID      uuid.UUID
+	StartOffset int32
+	EndOffset   int32
+	Handle      sql.NullString
+}
+
+func (q *Queries) ListChirpMentions(ctx context.Context, chirpIds []uuid.UUID) ([]ListChirpMentionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpMentions, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListChirpMentionsRow
+	for rows.Next() {
+		var i ListChirpMentionsRow
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.UserID,
+			&i.StartOffset,
+			&i.EndOffset,
+			&i.Handle,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
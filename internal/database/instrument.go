@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// QueryObserver is called after every statement with the sqlc query name,
+// how long it took and the error it returned, if any.
+type QueryObserver func(query string, duration time.Duration, err error)
+
+type instrumentedDB struct {
+	db      DBTX
+	observe QueryObserver
+}
+
+// Instrument wraps db so every statement run through Queries is reported
+// to observe.
+func Instrument(db DBTX, observe QueryObserver) DBTX {
+	return &instrumentedDB{db: db, observe: observe}
+}
+
+func (i *instrumentedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	res, err := i.db.ExecContext(ctx, query, args...)
+	i.observe(QueryName(query), time.Since(start), err)
+	return res, err
+}
+
+func (i *instrumentedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return i.db.PrepareContext(ctx, query)
+}
+
+func (i *instrumentedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := i.db.QueryContext(ctx, query, args...)
+	i.observe(QueryName(query), time.Since(start), err)
+	return rows, err
+}
+
+func (i *instrumentedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := i.db.QueryRowContext(ctx, query, args...)
+	i.observe(QueryName(query), time.Since(start), row.Err())
+	return row
+}
+
+// QueryName extracts the name from the "-- name: X :kind" header sqlc puts
+// in front of every generated query.
+func QueryName(query string) string {
+	header, _, _ := strings.Cut(query, "\n")
+	fields := strings.Fields(header)
+	if len(fields) < 3 || fields[0] != "--" || fields[1] != "name:" {
+		return "unknown"
+	}
+	return fields[2]
+}
@@ -0,0 +1,201 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: likes.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const likeChirp = `-- name: LikeChirp :execrows
+WITH inserted AS (
+    INSERT INTO likes (user_id, chirp_id, created_at)
+    VALUES ($1, $2, NOW())
+    ON CONFLICT DO NOTHING
+    RETURNING chirp_id
+)
+UPDATE chirps
+SET like_count = like_count + 1
+WHERE id IN (SELECT chirp_id FROM inserted)
+`
+
+type LikeChirpParams struct {
+	UserID  uuid.UUID
+	ChirpID uuid.UUID
+}
+
+func (q *Queries) LikeChirp(ctx context.Context, arg LikeChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, likeChirp, arg.UserID, arg.ChirpID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listLikedChirpIDs = `-- name: ListLikedChirpIDs :many
+SELECT chirp_id FROM likes
+WHERE user_id = $1 AND chirp_id = ANY($2::uuid[])
+`
+
+type ListLikedChirpIDsParams struct {
+	UserID   uuid.UUID
+	ChirpIds []uuid.UUID
+}
+
+func (q *Queries) ListLikedChirpIDs(ctx context.Context, arg ListLikedChirpIDsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, listLikedChirpIDs, arg.UserID, pq.Array(arg.ChirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var chirp_id uuid.UUID
+		if err := rows.Scan(&chirp_id); err != nil {
+			return nil, err
+		}
+		items = append(items, chirp_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRechirpedChirpIDs = `-- name: ListRechirpedChirpIDs :many
+SELECT chirp_id FROM rechirps
+WHERE user_id = $1 AND chirp_id = ANY($2::uuid[])
+`
+
+type ListRechirpedChirpIDsParams struct {
+	UserID   uuid.UUID
+	ChirpIds []uuid.UUID
+}
+
+func (q *Queries) ListRechirpedChirpIDs(ctx context.Context, arg ListRechirpedChirpIDsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, listRechirpedChirpIDs, arg.UserID, pq.Array(arg.ChirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var chirp_id uuid.UUID
+		if err := rows.Scan(&chirp_id); err != nil {
+			return nil, err
+		}
+		items = append(items, chirp_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rechirp = `-- name: Rechirp :execrows
+WITH inserted AS (
+    INSERT INTO rechirps (user_id, chirp_id, created_at)
+    VALUES ($1, $2, NOW())
+    ON CONFLICT DO NOTHING
+    RETURNING chirp_id
+)
+UPDATE chirps
+SET rechirp_count = rechirp_count + 1
+WHERE id IN (SELECT chirp_id FROM inserted)
+`
+
+type RechirpParams struct {
+	UserID  uuid.UUID
+	ChirpID uuid.UUID
+}
+
+func (q *Queries) Rechirp(ctx context.Context, arg RechirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rechirp, arg.UserID, arg.ChirpID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const recountUserReactions = `-- name: RecountUserReactions :exec
+UPDATE chirps
+SET like_count = (
+        SELECT count(*) FROM likes
+        JOIN users ON users.id = likes.user_id
+        WHERE likes.chirp_id = chirps.id AND users.deleted_at IS NULL
+    ),
+    rechirp_count = (
+        SELECT count(*) FROM rechirps
+        JOIN users ON users.id = rechirps.user_id
+        WHERE rechirps.chirp_id = chirps.id AND users.deleted_at IS NULL
+    )
+WHERE chirps.id IN (
+    SELECT likes.chirp_id FROM likes WHERE likes.user_id = $1
+    UNION
+    SELECT rechirps.chirp_id FROM rechirps WHERE rechirps.user_id = $1
+)
+`
+
+func (q *Queries) RecountUserReactions(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, recountUserReactions, userID)
+	return err
+}
+
+const undoRechirp = `-- name: UndoRechirp :execrows
+WITH deleted AS (
+    DELETE FROM rechirps
+    WHERE rechirps.user_id = $1 AND rechirps.chirp_id = $2
+    RETURNING chirp_id
+)
+UPDATE chirps
+SET rechirp_count = rechirp_count - 1
+WHERE id IN (SELECT chirp_id FROM deleted)
+`
+
+type UndoRechirpParams struct {
+	UserID  uuid.UUID
+	ChirpID uuid.UUID
+}
+
+func (q *Queries) UndoRechirp(ctx context.Context, arg UndoRechirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, undoRechirp, arg.UserID, arg.ChirpID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const unlikeChirp = `-- name: UnlikeChirp :execrows
+WITH deleted AS (
+    DELETE FROM likes
+    WHERE likes.user_id = $1 AND likes.chirp_id = $2
+    RETURNING chirp_id
+)
+UPDATE chirps
+SET like_count = like_count - 1
+WHERE id IN (SELECT chirp_id FROM deleted)
+`
+
+type UnlikeChirpParams struct {
+	UserID  uuid.UUID
+	ChirpID uuid.UUID
+}
+
+func (q *Queries) UnlikeChirp(ctx context.Context, arg UnlikeChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unlikeChirp, arg.UserID, arg.ChirpID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,176 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: login_attempts.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const clearLockout = `-- name: ClearLockout :execrows
+DELETE FROM login_lockouts
+WHERE key = $1
+`
+
+func (q *Queries) ClearLockout(ctx context.Context, key string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, clearLockout, key)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createLoginAttempt = `-- name: CreateLoginAttempt :exec
+INSERT INTO login_attempts (id, created_at, email, ip, user_agent, user_id, succeeded)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+`
+
+type CreateLoginAttemptParams struct {
+	Email     string
+	Ip        string
+	UserAgent string
+	UserID    uuid.NullUUID
+	Succeeded bool
+}
+
+func (q *Queries) CreateLoginAttempt(ctx context.Context, arg CreateLoginAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, createLoginAttempt,
+		arg.Email,
+		arg.Ip,
+		arg.UserAgent,
+		arg.UserID,
+		arg.Succeeded,
+	)
+	return err
+}
+
+const getActiveLockouts = `-- name: GetActiveLockouts :many
+SELECT key, created_at, updated_at, failures, locked_until FROM login_lockouts
+WHERE key = ANY($1::text[])
+AND locked_until > NOW()
+ORDER BY locked_until DESC
+`
+
+func (q *Queries) GetActiveLockouts(ctx context.Context, keys []string) ([]LoginLockout, error) {
+	rows, err := q.db.QueryContext(ctx, getActiveLockouts, pq.Array(keys))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LoginLockout
+	for rows.Next() {
+		var i LoginLockout
+		if err := rows.Scan(
+			&i.Key,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Failures,
+			&i.LockedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listActiveLockouts = `-- name: ListActiveLockouts :many
+SELECT key, created_at, updated_at, failures, locked_until FROM login_lockouts
+WHERE locked_until > NOW()
+ORDER BY locked_until DESC
+`
+
+func (q *Queries) ListActiveLockouts(ctx context.Context) ([]LoginLockout, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveLockouts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LoginLockout
+	for rows.Next() {
+		var i LoginLockout
+		if err := rows.Scan(
+			&i.Key,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Failures,
+			&i.LockedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockLogin = `-- name: LockLogin :exec
+UPDATE login_lockouts
+SET locked_until = NOW() + $1::integer * interval '1 second', updated_at = NOW()
+WHERE key = $2
+`
+
+type LockLoginParams struct {
+	LockSeconds int32
+	Key         string
+}
+
+func (q *Queries) LockLogin(ctx context.Context, arg LockLoginParams) error {
+	_, err := q.db.ExecContext(ctx, lockLogin, arg.LockSeconds, arg.Key)
+	return err
+}
+
+const recordLoginFailure = `-- name: RecordLoginFailure :one
+INSERT INTO login_lockouts (key, created_at, updated_at, failures, locked_until)
+VALUES (
+    $1,
+    NOW(),
+    NOW(),
+    1,
+    NULL
+)
+ON CONFLICT (key) DO UPDATE
+SET failures = CASE
+        WHEN login_lockouts.updated_at < NOW() - interval '1 day' THEN 1
+        ELSE login_lockouts.failures + 1
+    END,
+    updated_at = NOW()
+RETURNING key, created_at, updated_at, failures, locked_until
+`
+
+func (q *Queries) RecordLoginFailure(ctx context.Context, key string) (LoginLockout, error) {
+	row := q.db.QueryRowContext(ctx, recordLoginFailure, key)
+	var i LoginLockout
+	err := row.Scan(
+		&i.Key,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Failures,
+		&i.LockedUntil,
+	)
+	return i, err
+}
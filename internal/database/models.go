@@ -11,21 +11,145 @@ import (
 	"github.com/google/uuid"
 )
 
+type Attachment struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	ChirpID              uuid.UUID
+	ContentType          string
+	SizeBytes            int64
+	Width                int32
+	Height               int32
+	BlobKey              string
+	ThumbnailContentType string
+	ThumbnailWidth       int32
+	ThumbnailHeight      int32
+	ThumbnailKey         string
+}
+
 type Chirp struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Body         string
+	UserID       uuid.UUID
+	Hidden       bool
+	DeletedAt    sql.NullTime
+	DeletedBy    uuid.NullUUID
+	LikeCount    int32
+	RechirpCount int32
+	ParentID     uuid.NullUUID
+}
+
+type ChirpHashtag struct {
+	ChirpID     uuid.UUID
+	Tag         string
+	StartOffset int32
+	EndOffset   int32
+}
+
+type ChirpMention struct {
+	ChirpID     uuid.UUID
+	UserID      uuid.UUID
+	StartOffset int32
+	EndOffset   int32
+}
+
+type ChirpRevision struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	ReplacedAt time.Time
+	ChirpID    uuid.UUID
+	Body       string
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type Like struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type LoginAttempt struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
-	UpdatedAt time.Time
-	Body      string
-	UserID    uuid.UUID
+	Email     string
+	Ip        string
+	UserAgent string
+	UserID    uuid.NullUUID
+	Succeeded bool
 }
 
-type RefreshToken struct {
-	Token     string
+type LoginLockout struct {
+	Key         string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Failures    int32
+	LockedUntil sql.NullTime
+}
+
+type ModerationRule struct {
+	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	Term      string
+	Action    string
+}
+
+type Plan struct {
+	Name            string
+	MaxChirpLength  int32
+	EditChirps      bool
+	RateLimitFactor int32
+	UploadFactor    int32
+}
+
+type Rechirp struct {
 	UserID    uuid.UUID
-	ExpiresAt time.Time
-	RevokedAt sql.NullTime
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type RefreshToken struct {
+	Token      string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	ExpiresAt  time.Time
+	RevokedAt  sql.NullTime
+	FamilyID   uuid.UUID
+	ReplacedBy sql.NullString
+	ID         uuid.UUID
+	UserAgent  string
+	Ip         string
+	LastUsedAt time.Time
+}
+
+type Report struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	ChirpID    uuid.UUID
+	ReporterID uuid.NullUUID
+	Reason     string
+	Status     string
+	ResolvedBy uuid.NullUUID
+	ResolvedAt sql.NullTime
+}
+
+type Subscription struct {
+	UserID           uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Status           string
+	StartedAt        time.Time
+	CurrentPeriodEnd sql.NullTime
+	CanceledAt       sql.NullTime
+	EndedAt          sql.NullTime
 }
 
 type User struct {
@@ -35,4 +159,7 @@ type User struct {
 	Email          string
 	HashedPassword string
 	IsChirpyRed    bool
+	Role           string
+	DeletedAt      sql.NullTime
+	Handle         sql.NullString
 }
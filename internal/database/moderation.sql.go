@@ -0,0 +1,115 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: moderation.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const createModerationRule = `-- name: CreateModerationRule :one
+INSERT INTO moderation_rules (id, created_at, updated_at, term, action)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2
+)
+RETURNING id, created_at, updated_at, term, action
+`
+
+type CreateModerationRuleParams struct {
+	Term   string
+	Action string
+}
+
+func (q *Queries) CreateModerationRule(ctx context.Context, arg CreateModerationRuleParams) (ModerationRule, error) {
+	row := q.db.QueryRowContext(ctx, createModerationRule, arg.Term, arg.Action)
+	var i ModerationRule
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Term,
+		&i.Action,
+	)
+	return i, err
+}
+
+const deleteModerationRule = `-- name: DeleteModerationRule :execrows
+DELETE FROM moderation_rules
+WHERE id = $1
+`
+
+func (q *Queries) DeleteModerationRule(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteModerationRule, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listModerationRules = `-- name: ListModerationRules :many
+SELECT id, created_at, updated_at, term, action FROM moderation_rules
+ORDER BY term ASC
+`
+
+func (q *Queries) ListModerationRules(ctx context.Context) ([]ModerationRule, error) {
+	rows, err := q.db.QueryContext(ctx, listModerationRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ModerationRule
+	for rows.Next() {
+		var i ModerationRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Term,
+			&i.Action,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateModerationRule = `-- name: UpdateModerationRule :one
+UPDATE moderation_rules
+SET term = $2, action = $3, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, term, action
+`
+
+type UpdateModerationRuleParams struct {
+	ID     uuid.UUID
+	Term   string
+	Action string
+}
+
+func (q *Queries) UpdateModerationRule(ctx context.Context, arg UpdateModerationRuleParams) (ModerationRule, error) {
+	row := q.db.QueryRowContext(ctx, updateModerationRule, arg.ID, arg.Term, arg.Action)
+	var i ModerationRule
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Term,
+		&i.Action,
+	)
+	return i, err
+}
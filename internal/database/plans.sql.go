@@ -0,0 +1,27 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: plans.sql
+
+package database
+
+import (
+	"context"
+)
+
+const getPlan = `-- name: GetPlan :one
+SELECT name, max_chirp_length, edit_chirps, rate_limit_factor, upload_factor FROM plans WHERE name = $1
+`
+
+func (q *Queries) GetPlan(ctx context.Context, name string) (Plan, error) {
+	row := q.db.QueryRowContext(ctx, getPlan, name)
+	var i Plan
+	err := row.Scan(
+		&i.Name,
+		&i.MaxChirpLength,
+		&i.EditChirps,
+		&i.RateLimitFactor,
+		&i.UploadFactor,
+	)
+	return i, err
+}
@@ -0,0 +1,141 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: reports.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createReport = `-- name: CreateReport :one
+INSERT INTO reports (id, created_at, updated_at, chirp_id, reporter_id, reason, status)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    'open'
+)
+RETURNING id, created_at, updated_at, chirp_id, reporter_id, reason, status, resolved_by, resolved_at
+`
+
+type CreateReportParams struct {
+	ChirpID    uuid.UUID
+	ReporterID uuid.NullUUID
+	Reason     string
+}
+
+func (q *Queries) CreateReport(ctx context.Context, arg CreateReportParams) (Report, error) {
+	row := q.db.QueryRowContext(ctx, createReport, arg.ChirpID, arg.ReporterID, arg.Reason)
+	var i Report
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ChirpID,
+		&i.ReporterID,
+		&i.Reason,
+		&i.Status,
+		&i.ResolvedBy,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
+
+const listReportQueue = `-- name: ListReportQueue :many
+SELECT
+    chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden, chirps.deleted_at, chirps.deleted_by, chirps.like_count, chirps.rechirp_count, chirps.parent_id,
+    COUNT(reports.id) AS report_count,
+    array_agg(reports.reason ORDER BY reports.created_at)::text[] AS reasons,
+    MIN(reports.created_at)::timestamp AS first_reported_at
+FROM reports
+JOIN chirps ON chirps.id = reports.chirp_id
+WHERE reports.status = 'open' AND chirps.deleted_at IS NULL
+GROUP BY chirps.id
+ORDER BY report_count DESC, first_reported_at ASC
+LIMIT $1
+`
+
+type ListReportQueueRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Body            string
+	UserID          uuid.UUID
+	Hidden          bool
+	DeletedAt       sql.NullTime
+	DeletedBy       uuid.NullUUID
+	LikeCount       int32
+	RechirpCount    int32
+	ParentID        uuid.NullUUID
+	ReportCount     int64
+	Reasons         []string
+	FirstReportedAt time.Time
+}
+
+func (q *Queries) ListReportQueue(ctx context.Context, limit int32) ([]ListReportQueueRow, error) {
+	rows, err := q.db.QueryContext(ctx, listReportQueue, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListReportQueueRow
+	for rows.Next() {
+		var i ListReportQueueRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.Hidden,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.LikeCount,
+			&i.RechirpCount,
+			&i.ParentID,
+			&i.ReportCount,
+			pq.Array(&i.Reasons),
+			&i.FirstReportedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resolveReports = `-- name: ResolveReports :execrows
+UPDATE reports
+SET status = $2, resolved_by = $3, resolved_at = NOW(), updated_at = NOW()
+WHERE chirp_id = $1 AND status = 'open'
+`
+
+type ResolveReportsParams struct {
+	ChirpID    uuid.UUID
+	Status     string
+	ResolvedBy uuid.NullUUID
+}
+
+func (q *Queries) ResolveReports(ctx context.Context, arg ResolveReportsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, resolveReports, arg.ChirpID, arg.Status, arg.ResolvedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
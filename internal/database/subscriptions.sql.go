@@ -0,0 +1,165 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: subscriptions.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const cancelSubscription = `-- name: CancelSubscription :one
+UPDATE subscriptions
+SET updated_at = NOW(),
+    canceled_at = NOW(),
+    current_period_end = COALESCE($2, current_period_end),
+    status = CASE
+        WHEN COALESCE($2, current_period_end) <= NOW() THEN 'expired'
+        ELSE 'canceled'
+    END,
+    ended_at = CASE
+        WHEN COALESCE($2, current_period_end) <= NOW() THEN NOW()
+    END
+WHERE user_id = $1 AND status = 'active'
+RETURNING user_id, created_at, updated_at, status, started_at, current_period_end, canceled_at, ended_at
+`
+
+type CancelSubscriptionParams struct {
+	UserID           uuid.UUID
+	CurrentPeriodEnd sql.NullTime
+}
+
+// Without a known period end the subscription stays canceled, and keeps its
+// benefits, until Polka downgrades the user.
+func (q *Queries) CancelSubscription(ctx context.Context, arg CancelSubscriptionParams) (Subscription, error) {
+	row := q.db.QueryRowContext(ctx, cancelSubscription, arg.UserID, arg.CurrentPeriodEnd)
+	var i Subscription
+	err := row.Scan(
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.StartedAt,
+		&i.CurrentPeriodEnd,
+		&i.CanceledAt,
+		&i.EndedAt,
+	)
+	return i, err
+}
+
+const endSubscription = `-- name: EndSubscription :one
+UPDATE subscriptions
+SET updated_at = NOW(), status = $2, ended_at = NOW()
+WHERE user_id = $1 AND status IN ('active', 'canceled')
+RETURNING user_id, created_at, updated_at, status, started_at, current_period_end, canceled_at, ended_at
+`
+
+type EndSubscriptionParams struct {
+	UserID uuid.UUID
+	Status string
+}
+
+func (q *Queries) EndSubscription(ctx context.Context, arg EndSubscriptionParams) (Subscription, error) {
+	row := q.db.QueryRowContext(ctx, endSubscription, arg.UserID, arg.Status)
+	var i Subscription
+	err := row.Scan(
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.StartedAt,
+		&i.CurrentPeriodEnd,
+		&i.CanceledAt,
+		&i.EndedAt,
+	)
+	return i, err
+}
+
+const expireSubscriptions = `-- name: ExpireSubscriptions :execrows
+WITH expired AS (
+    UPDATE subscriptions
+    SET updated_at = NOW(), status = 'expired', ended_at = current_period_end
+    WHERE status IN ('active', 'canceled') AND current_period_end <= NOW()
+    RETURNING user_id
+)
+UPDATE users
+SET is_chirpy_red = false, updated_at = NOW()
+FROM expired
+WHERE users.id = expired.user_id
+`
+
+func (q *Queries) ExpireSubscriptions(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, expireSubscriptions)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getSubscription = `-- name: GetSubscription :one
+SELECT user_id, created_at, updated_at, status, started_at, current_period_end, canceled_at, ended_at FROM subscriptions WHERE user_id = $1
+`
+
+func (q *Queries) GetSubscription(ctx context.Context, userID uuid.UUID) (Subscription, error) {
+	row := q.db.QueryRowContext(ctx, getSubscription, userID)
+	var i Subscription
+	err := row.Scan(
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.StartedAt,
+		&i.CurrentPeriodEnd,
+		&i.CanceledAt,
+		&i.EndedAt,
+	)
+	return i, err
+}
+
+const startSubscription = `-- name: StartSubscription :one
+INSERT INTO subscriptions (user_id, created_at, updated_at, status, started_at, current_period_end)
+VALUES (
+    $1,
+    NOW(),
+    NOW(),
+    'active',
+    NOW(),
+    $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET updated_at = NOW(),
+    status = 'active',
+    started_at = CASE
+        WHEN subscriptions.status IN ('active', 'canceled') THEN subscriptions.started_at
+        ELSE NOW()
+    END,
+    current_period_end = EXCLUDED.current_period_end,
+    canceled_at = NULL,
+    ended_at = NULL
+RETURNING user_id, created_at, updated_at, status, started_at, current_period_end, canceled_at, ended_at
+`
+
+type StartSubscriptionParams struct {
+	UserID           uuid.UUID
+	CurrentPeriodEnd sql.NullTime
+}
+
+func (q *Queries) StartSubscription(ctx context.Context, arg StartSubscriptionParams) (Subscription, error) {
+	row := q.db.QueryRowContext(ctx, startSubscription, arg.UserID, arg.CurrentPeriodEnd)
+	var i Subscription
+	err := row.Scan(
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.StartedAt,
+		&i.CurrentPeriodEnd,
+		&i.CanceledAt,
+		&i.EndedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,108 @@
+// Package entities finds #hashtags and @mentions in chirp bodies.
+package entities
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	maxHashtagLength = 50
+	minHandleLength  = 3
+	maxHandleLength  = 30
+)
+
+type Kind string
+
+const (
+	KindHashtag Kind = "hashtag"
+	KindMention Kind = "mention"
+)
+
+// Entity is a hashtag or mention in a text. Start and End count Unicode
+// code points rather than bytes so clients in any language can slice the
+// text with them. Text is lower cased and has no leading # or @.
+type Entity struct {
+	Kind  Kind
+	Text  string
+	Start int
+	End   int
+}
+
+// Extract returns the hashtags and mentions of text in order. A sigil only
+// starts an entity at the beginning of a word, so "a@b.com" and "c#" are
+// left alone.
+func Extract(text string) []Entity {
+	var found []Entity
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); i++ {
+		var kind Kind
+		switch runes[i] {
+		case '#':
+			kind = KindHashtag
+		case '@':
+			kind = KindMention
+		default:
+			continue
+		}
+
+		if i > 0 && isWordRune(runes[i-1]) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+
+		word := string(runes[i+1 : end])
+		if (kind == KindHashtag && ValidHashtag(word)) || (kind == KindMention && ValidHandle(word)) {
+			found = append(found, Entity{
+				Kind:  kind,
+				Text:  Normalize(word),
+				Start: i,
+				End:   end,
+			})
+		}
+		i = end - 1
+	}
+
+	return found
+}
+
+// ValidHandle reports whether handle can be used as a user's handle: 3 to
+// 30 ASCII letters, digits or underscores.
+func ValidHandle(handle string) bool {
+	if len(handle) < minHandleLength || len(handle) > maxHandleLength {
+		return false
+	}
+	for _, r := range handle {
+		if r > unicode.MaxASCII || !isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Normalize returns the form hashtags and handles are compared in.
+func Normalize(text string) string {
+	return strings.ToLower(text)
+}
+
+// ValidHashtag reports whether tag, without the #, is a hashtag: up to 50
+// letters, digits or underscores in any script, at least one of them a
+// letter so "#1" in "we're #1" is not a tag.
+func ValidHashtag(tag string) bool {
+	if tag == "" || len([]rune(tag)) > maxHashtagLength {
+		return false
+	}
+	if strings.IndexFunc(tag, func(r rune) bool { return !isWordRune(r) }) >= 0 {
+		return false
+	}
+	return strings.IndexFunc(tag, unicode.IsLetter) >= 0
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
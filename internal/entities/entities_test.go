@@ -0,0 +1,103 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []Entity
+	}{
+		{
+			name: "no entities",
+			text: "just a chirp",
+		},
+		{
+			name: "hashtag and mention",
+			text: "Hi @Alice_1, see #GoLang!",
+			expected: []Entity{
+				{Kind: KindMention, Text: "alice_1", Start: 3, End: 11},
+				{Kind: KindHashtag, Text: "golang", Start: 17, End: 24},
+			},
+		},
+		{
+			name: "offsets count code points",
+			text: "héllo #café",
+			expected: []Entity{
+				{Kind: KindHashtag, Text: "café", Start: 6, End: 11},
+			},
+		},
+		{
+			name: "sigil inside a word",
+			text: "mail bob@example.com about c#",
+		},
+		{
+			name: "numeric hashtag and short handle",
+			text: "we're #1 says @al",
+		},
+		{
+			name: "repeated sigils",
+			text: "##go @@bob",
+			expected: []Entity{
+				{Kind: KindHashtag, Text: "go", Start: 1, End: 4},
+				{Kind: KindMention, Text: "bob", Start: 6, End: 10},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Extract(tt.text)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Extract(%q) = %+v, want %+v", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidHandle(t *testing.T) {
+	tests := []struct {
+		handle   string
+		expected bool
+	}{
+		{"bob", true},
+		{"Bob_42", true},
+		{"al", false},
+		{"this_handle_is_far_too_long_ok", true},
+		{"this_handle_is_far_too_long_ok1", false},
+		{"béa", false},
+		{"bob!", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidHandle(tt.handle); got != tt.expected {
+			t.Errorf("ValidHandle(%q) = %v, want %v", tt.handle, got, tt.expected)
+		}
+	}
+}
+
+func TestValidHashtag(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected bool
+	}{
+		{"golang", true},
+		{"café", true},
+		{"日本語", true},
+		{"go_1", true},
+		{"1", false},
+		{"", false},
+		{"no-dash", false},
+		{"abcdefghijabcdefghijabcdefghijabcdefghijabcdefghij", true},
+		{"abcdefghijabcdefghijabcdefghijabcdefghijabcdefghijk", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidHashtag(tt.tag); got != tt.expected {
+			t.Errorf("ValidHashtag(%q) = %v, want %v", tt.tag, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Package media validates uploaded images and makes thumbnails of them
+// using only the standard library image packages.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"slices"
+)
+
+const (
+	// MaxPixels bounds the decoded size of an image, since a small file can
+	// expand into a huge bitmap.
+	MaxPixels = 40_000_000
+
+	ThumbnailSize = 320
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrTooManyPixels   = fmt.Errorf("image has more than %d pixels", MaxPixels)
+)
+
+var allowedTypes = []string{"image/png", "image/jpeg", "image/gif"}
+
+// Image describes an uploaded image and holds its encoded thumbnail.
+type Image struct {
+	ContentType string
+	Width       int
+	Height      int
+
+	Thumbnail            []byte
+	ThumbnailContentType string
+	ThumbnailWidth       int
+	ThumbnailHeight      int
+}
+
+// Process sniffs the type of data rather than trusting the client, checks
+// that it decodes as an image and scales it down to fit in a square of
+// ThumbnailSize pixels. Only the first frame of a GIF is used.
+func Process(data []byte) (Image, error) {
+	contentType := http.DetectContentType(data)
+	if !slices.Contains(allowedTypes, contentType) {
+		return Image{}, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return Image{}, ErrTooManyPixels
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+
+	thumb := Thumbnail(src, ThumbnailSize)
+
+	var buf bytes.Buffer
+	thumbType := "image/png"
+	if contentType == "image/jpeg" {
+		thumbType = "image/jpeg"
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 80})
+	} else {
+		err = png.Encode(&buf, thumb)
+	}
+	if err != nil {
+		return Image{}, err
+	}
+
+	return Image{
+		ContentType:          contentType,
+		Width:                cfg.Width,
+		Height:               cfg.Height,
+		Thumbnail:            buf.Bytes(),
+		ThumbnailContentType: thumbType,
+		ThumbnailWidth:       thumb.Bounds().Dx(),
+		ThumbnailHeight:      thumb.Bounds().Dy(),
+	}, nil
+}
+
+// Thumbnail scales src down to fit in a size by size square, keeping its
+// aspect ratio. Every thumbnail pixel is the average of the premultiplied
+// source pixels it covers. Images that already fit are copied unchanged.
+func Thumbnail(src image.Image, size int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	tw, th := w, h
+	if w > size || h > size {
+		if w >= h {
+			tw, th = size, max(1, h*size/w)
+		} else {
+			tw, th = max(1, w*size/h), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0 := b.Min.Y + y*h/th
+		y1 := max(y0+1, b.Min.Y+(y+1)*h/th)
+		for x := 0; x < tw; x++ {
+			x0 := b.Min.X + x*w/tw
+			x1 := max(x0+1, b.Min.X+(x+1)*w/tw)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					bl += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+
+			dst.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
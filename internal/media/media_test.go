@@ -0,0 +1,100 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcess(t *testing.T) {
+	tests := []struct {
+		name            string
+		data            []byte
+		expectedError   error
+		expectedThumbW  int
+		expectedThumbH  int
+		expectedWidth   int
+		expectedContent string
+	}{
+		{
+			name:            "wide image is scaled down",
+			data:            encodePNG(t, 640, 160),
+			expectedThumbW:  320,
+			expectedThumbH:  80,
+			expectedWidth:   640,
+			expectedContent: "image/png",
+		},
+		{
+			name:            "tall image is scaled down",
+			data:            encodePNG(t, 100, 1000),
+			expectedThumbW:  32,
+			expectedThumbH:  320,
+			expectedWidth:   100,
+			expectedContent: "image/png",
+		},
+		{
+			name:            "small image is kept",
+			data:            encodePNG(t, 10, 20),
+			expectedThumbW:  10,
+			expectedThumbH:  20,
+			expectedWidth:   10,
+			expectedContent: "image/png",
+		},
+		{
+			name:          "not an image",
+			data:          []byte("<html><body>hi</body></html>"),
+			expectedError: ErrUnsupportedType,
+		},
+		{
+			name:          "truncated png",
+			data:          encodePNG(t, 10, 10)[:20],
+			expectedError: ErrUnsupportedType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Process(tt.data)
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.expectedError)
+			}
+			if err != nil {
+				return
+			}
+
+			if img.ContentType != tt.expectedContent || img.Width != tt.expectedWidth {
+				t.Errorf("got %s %dpx wide, want %s %dpx wide", img.ContentType, img.Width, tt.expectedContent, tt.expectedWidth)
+			}
+
+			thumb, err := png.Decode(bytes.NewReader(img.Thumbnail))
+			if err != nil {
+				t.Fatalf("thumbnail doesn't decode: %v", err)
+			}
+			b := thumb.Bounds()
+			if b.Dx() != tt.expectedThumbW || b.Dy() != tt.expectedThumbH {
+				t.Errorf("thumbnail is %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.expectedThumbW, tt.expectedThumbH)
+			}
+			if r, _, _, _ := thumb.At(0, 0).RGBA(); r != 0xffff {
+				t.Errorf("thumbnail color was not preserved, red = %#x", r)
+			}
+		})
+	}
+}
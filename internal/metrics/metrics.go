@@ -0,0 +1,307 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are latency buckets in seconds, matching the ones used by
+// the Prometheus client libraries.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+// Registry holds every metric and renders them in the Prometheus text
+// exposition format.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	buf := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(buf)
+	}
+	return buf.Flush()
+}
+
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		r.WriteText(w)
+	})
+}
+
+// family is the label bookkeeping shared by every metric type.
+type family[T any] struct {
+	name     string
+	help     string
+	kind     string
+	labels   []string
+	newChild func() T
+
+	mu       sync.Mutex
+	children map[string]T
+	values   map[string][]string
+}
+
+func (f *family[T]) with(values ...string) T {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labels), len(values)))
+	}
+
+	key := strings.Join(values, "\xff")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	child, ok := f.children[key]
+	if !ok {
+		child = f.newChild()
+		f.children[key] = child
+		f.values[key] = append([]string(nil), values...)
+	}
+	return child
+}
+
+func (f *family[T]) each(fn func(values []string, child T)) {
+	f.mu.Lock()
+	keys := make([]string, 0, len(f.children))
+	for k := range f.children {
+		keys = append(keys, k)
+	}
+	f.mu.Unlock()
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		f.mu.Lock()
+		child, values := f.children[k], f.values[k]
+		f.mu.Unlock()
+		fn(values, child)
+	}
+}
+
+func (f *family[T]) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+}
+
+func newFamily[T any](name, help, kind string, labels []string, newChild func() T) *family[T] {
+	return &family[T]{
+		name:     name,
+		help:     help,
+		kind:     kind,
+		labels:   labels,
+		newChild: newChild,
+		children: map[string]T{},
+		values:   map[string][]string{},
+	}
+}
+
+// value is a float64 that can be updated from several goroutines.
+type value struct {
+	mu sync.Mutex
+	v  float64
+}
+
+func (v *value) add(delta float64) {
+	v.mu.Lock()
+	v.v += delta
+	v.mu.Unlock()
+}
+
+func (v *value) set(val float64) {
+	v.mu.Lock()
+	v.v = val
+	v.mu.Unlock()
+}
+
+func (v *value) get() float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.v
+}
+
+type Counter struct{ value }
+
+func (c *Counter) Inc() { c.add(1) }
+
+// Add increases the counter; negative deltas are ignored because counters
+// only go up.
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		return
+	}
+	c.add(delta)
+}
+
+type CounterVec struct{ f *family[*Counter] }
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{f: newFamily(name, help, "counter", labels, func() *Counter { return &Counter{} })}
+	r.register(c)
+	return c
+}
+
+func (c *CounterVec) WithLabelValues(values ...string) *Counter {
+	return c.f.with(values...)
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.f.writeHeader(w)
+	c.f.each(func(values []string, child *Counter) {
+		fmt.Fprintf(w, "%s%s %s\n", c.f.name, formatLabels(c.f.labels, values), formatFloat(child.get()))
+	})
+}
+
+type Gauge struct{ value }
+
+func (g *Gauge) Inc()            { g.add(1) }
+func (g *Gauge) Dec()            { g.add(-1) }
+func (g *Gauge) Set(val float64) { g.set(val) }
+
+type GaugeVec struct{ f *family[*Gauge] }
+
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{f: newFamily(name, help, "gauge", labels, func() *Gauge { return &Gauge{} })}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeVec) WithLabelValues(values ...string) *Gauge {
+	return g.f.with(values...)
+}
+
+func (g *GaugeVec) write(w *bufio.Writer) {
+	g.f.writeHeader(w)
+	g.f.each(func(values []string, child *Gauge) {
+		fmt.Fprintf(w, "%s%s %s\n", g.f.name, formatLabels(g.f.labels, values), formatFloat(child.get()))
+	})
+}
+
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func (h *Histogram) Observe(val float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, upper := range h.buckets {
+		if val <= upper {
+			h.counts[i]++
+		}
+	}
+	h.sum += val
+	h.count++
+}
+
+type HistogramVec struct {
+	f       *family[*Histogram]
+	buckets []float64
+}
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	h := &HistogramVec{buckets: buckets}
+	h.f = newFamily(name, help, "histogram", labels, func() *Histogram {
+		return &Histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+	})
+	r.register(h)
+	return h
+}
+
+func (h *HistogramVec) WithLabelValues(values ...string) *Histogram {
+	return h.f.with(values...)
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.f.writeHeader(w)
+	names := append(append([]string(nil), h.f.labels...), "le")
+
+	h.f.each(func(values []string, child *Histogram) {
+		child.mu.Lock()
+		counts := append([]uint64(nil), child.counts...)
+		sum, count := child.sum, child.count
+		child.mu.Unlock()
+
+		bucketValues := append(append([]string(nil), values...), "")
+		for i, upper := range h.buckets {
+			bucketValues[len(values)] = formatFloat(upper)
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.f.name, formatLabels(names, bucketValues), counts[i])
+		}
+		bucketValues[len(values)] = "+Inf"
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.f.name, formatLabels(names, bucketValues), count)
+
+		base := formatLabels(h.f.labels, values)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.f.name, base, formatFloat(sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.f.name, base, count)
+	})
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf("%s=\"%s\"", name, escapeLabel(values[i]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
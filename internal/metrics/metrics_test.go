@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	registry := NewRegistry()
+
+	requests := registry.NewCounterVec("requests_total", "Requests handled.", "route", "code")
+	requests.WithLabelValues("GET /api/chirps", "200").Inc()
+	requests.WithLabelValues("GET /api/chirps", "200").Add(2)
+	requests.WithLabelValues(`say "hi"`, "500").Inc()
+
+	inFlight := registry.NewGaugeVec("in_flight", "Requests in flight.", "route")
+	inFlight.WithLabelValues("POST /api/chirps").Inc()
+	inFlight.WithLabelValues("POST /api/chirps").Inc()
+	inFlight.WithLabelValues("POST /api/chirps").Dec()
+
+	latency := registry.NewHistogramVec("latency_seconds", "Latency.", []float64{0.5, 0.1}, "route")
+	latency.WithLabelValues("GET /api/healthz").Observe(0.05)
+	latency.WithLabelValues("GET /api/healthz").Observe(0.3)
+	latency.WithLabelValues("GET /api/healthz").Observe(2)
+
+	var sb strings.Builder
+	err := registry.WriteText(&sb)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"# TYPE requests_total counter",
+		`requests_total{route="GET /api/chirps",code="200"} 3`,
+		`requests_total{route="say \"hi\"",code="500"} 1`,
+		"# TYPE in_flight gauge",
+		`in_flight{route="POST /api/chirps"} 1`,
+		"# TYPE latency_seconds histogram",
+		`latency_seconds_bucket{route="GET /api/healthz",le="0.1"} 1`,
+		`latency_seconds_bucket{route="GET /api/healthz",le="0.5"} 2`,
+		`latency_seconds_bucket{route="GET /api/healthz",le="+Inf"} 3`,
+		`latency_seconds_sum{route="GET /api/healthz"} 2.35`,
+		`latency_seconds_count{route="GET /api/healthz"} 3`,
+	}
+
+	out := sb.String()
+	for _, line := range expected {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("output is missing %q:\n%s", line, out)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package moderation
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+type Action string
+
+const (
+	ActionMask   Action = "mask"
+	ActionFlag   Action = "flag"
+	ActionReject Action = "reject"
+)
+
+func (a Action) Valid() bool {
+	return a == ActionMask || a == ActionFlag || a == ActionReject
+}
+
+// Match is a rule that fired on part of the text. Filters report Start and
+// End as byte offsets; Moderate converts them to Unicode code points, the
+// unit entities use, before returning.
+type Match struct {
+	Rule   string `json:"rule"`
+	Action Action `json:"action"`
+	Term   string `json:"term"`
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+}
+
+// Filter inspects the tokenized text and reports every match it finds.
+type Filter interface {
+	Check(text string, tokens []Token) []Match
+}
+
+// Result is the outcome of running the pipeline. Text has every masked
+// match replaced; it should not be stored when Rejected is set.
+type Result struct {
+	Text     string
+	Rejected bool
+	Flagged  bool
+	Matches  []Match
+}
+
+type Pipeline struct {
+	filters []Filter
+}
+
+func NewPipeline(filters ...Filter) *Pipeline {
+	return &Pipeline{filters: filters}
+}
+
+const mask = "****"
+
+func (p *Pipeline) Moderate(text string) Result {
+	tokens := Tokenize(text)
+
+	var matches []Match
+	for _, f := range p.filters {
+		matches = append(matches, f.Check(text, tokens)...)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+
+	result := Result{Matches: matches}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		switch m.Action {
+		case ActionReject:
+			result.Rejected = true
+		case ActionFlag:
+			result.Flagged = true
+		case ActionMask:
+			if m.Start < last {
+				continue
+			}
+			sb.WriteString(text[last:m.Start])
+			sb.WriteString(mask)
+			last = m.End
+		}
+	}
+	sb.WriteString(text[last:])
+	result.Text = sb.String()
+
+	for i, m := range matches {
+		matches[i].Start = utf8.RuneCountInString(text[:m.Start])
+		matches[i].End = matches[i].Start + utf8.RuneCountInString(text[m.Start:m.End])
+	}
+
+	return result
+}
@@ -0,0 +1,105 @@
+package moderation
+
+import (
+	"testing"
+)
+
+func TestModerate(t *testing.T) {
+	pipeline := NewPipeline(NewWordList([]Rule{
+		{Term: "kerfuffle", Action: ActionMask},
+		{Term: "sharbert", Action: ActionMask},
+		{Term: "fornax", Action: ActionFlag},
+		{Term: "spam", Action: ActionReject},
+	}))
+
+	tests := []struct {
+		name             string
+		text             string
+		expectedText     string
+		expectedRejected bool
+		expectedFlagged  bool
+	}{
+		{
+			name:         "clean text",
+			text:         "I had something interesting for breakfast",
+			expectedText: "I had something interesting for breakfast",
+		},
+		{
+			name:         "case folding",
+			text:         "This is a KerFuffle opinion",
+			expectedText: "This is a **** opinion",
+		},
+		{
+			name:         "punctuation",
+			text:         "What a Kerfuffle! Sharbert.",
+			expectedText: "What a ****! ****.",
+		},
+		{
+			name:         "newlines and tabs",
+			text:         "kerfuffle\nsharbert\tok",
+			expectedText: "****\n****\tok",
+		},
+		{
+			name:         "leetspeak",
+			text:         "k3rfuffl3 and sh@rb3rt",
+			expectedText: "**** and ****",
+		},
+		{
+			name:         "unicode words around matches",
+			text:         "Grüße, kerfuffle über alles",
+			expectedText: "Grüße, **** über alles",
+		},
+		{
+			name:            "flag",
+			text:            "look at fornax",
+			expectedText:    "look at fornax",
+			expectedFlagged: true,
+		},
+		{
+			name:             "reject",
+			text:             "buy cheap sp4m",
+			expectedText:     "buy cheap sp4m",
+			expectedRejected: true,
+		},
+		{
+			name:         "substring is not a match",
+			text:         "kerfuffled",
+			expectedText: "kerfuffled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := pipeline.Moderate(tt.text)
+
+			if result.Text != tt.expectedText {
+				t.Errorf("got text %q, want %q", result.Text, tt.expectedText)
+			}
+
+			if result.Rejected != tt.expectedRejected {
+				t.Errorf("got rejected %v, want %v", result.Rejected, tt.expectedRejected)
+			}
+
+			if result.Flagged != tt.expectedFlagged {
+				t.Errorf("got flagged %v, want %v", result.Flagged, tt.expectedFlagged)
+			}
+		})
+	}
+}
+
+func TestModerateMatchOffsets(t *testing.T) {
+	pipeline := NewPipeline(NewWordList([]Rule{
+		{Term: "kerfuffle", Action: ActionMask},
+	}))
+
+	result := pipeline.Moderate("Grüße, kerfuffle")
+
+	if len(result.Matches) != 1 {
+		t.Fatalf("got %d matches, want 1", len(result.Matches))
+	}
+
+	m := result.Matches[0]
+	if m.Start != 7 || m.End != 16 {
+		t.Errorf("got offsets %d-%d, want 7-16", m.Start, m.End)
+	}
+}
@@ -0,0 +1,84 @@
+package moderation
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Token is a word of the original text. Start and End are byte offsets, so
+// text[Start:End] is the word exactly as the author wrote it.
+type Token struct {
+	Text       string
+	Normalized string
+	Start      int
+	End        int
+}
+
+// leet maps the usual character substitutions back to the letter they
+// stand in for.
+var leet = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'@': 'a',
+	'$': 's',
+	'!': 'i',
+}
+
+// Tokenize splits text into words on anything that is not a letter or a
+// digit. Symbols used in leetspeak count as part of a word when they
+// follow a letter or digit, so "sh@rbert" is one word but "@chirpy" is not.
+func Tokenize(text string) []Token {
+	var tokens []Token
+	start := -1
+
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		word := strings.TrimRight(text[start:end], "@$!")
+		if word != "" {
+			tokens = append(tokens, Token{
+				Text:       word,
+				Normalized: Normalize(word),
+				Start:      start,
+				End:        start + len(word),
+			})
+		}
+		start = -1
+	}
+
+	for i, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if start < 0 {
+				start = i
+			}
+		case start >= 0 && (r == '@' || r == '$' || r == '!'):
+		default:
+			flush(i)
+		}
+	}
+	flush(len(text))
+
+	return tokens
+}
+
+// Normalize case folds word and undoes leetspeak so variants of a term
+// compare equal.
+func Normalize(word string) string {
+	var sb strings.Builder
+	sb.Grow(len(word))
+
+	for _, r := range strings.ToLower(word) {
+		if mapped, ok := leet[r]; ok {
+			r = mapped
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
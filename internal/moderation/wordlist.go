@@ -0,0 +1,104 @@
+package moderation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Rule applies Action to every word that normalizes to the same form as
+// Term.
+type Rule struct {
+	ID     string
+	Term   string
+	Action Action
+}
+
+type WordList struct {
+	rules map[string]Rule
+}
+
+// NewWordList indexes rules by normalized term. When two rules share a
+// term the stricter action wins.
+func NewWordList(rules []Rule) *WordList {
+	w := &WordList{rules: map[string]Rule{}}
+	for _, rule := range rules {
+		key := Normalize(rule.Term)
+		if existing, ok := w.rules[key]; ok && severity(existing.Action) >= severity(rule.Action) {
+			continue
+		}
+		w.rules[key] = rule
+	}
+	return w
+}
+
+func severity(a Action) int {
+	switch a {
+	case ActionReject:
+		return 3
+	case ActionFlag:
+		return 2
+	case ActionMask:
+		return 1
+	}
+	return 0
+}
+
+func (w *WordList) Check(text string, tokens []Token) []Match {
+	var matches []Match
+	for _, token := range tokens {
+		rule, ok := w.rules[token.Normalized]
+		if !ok {
+			continue
+		}
+
+		id := rule.ID
+		if id == "" {
+			id = "wordlist:" + rule.Term
+		}
+
+		matches = append(matches, Match{
+			Rule:   id,
+			Action: rule.Action,
+			Term:   rule.Term,
+			Start:  token.Start,
+			End:    token.End,
+		})
+	}
+	return matches
+}
+
+// ParseWordList reads one rule per line: a term optionally followed by an
+// action, which defaults to mask. Blank lines and lines starting with #
+// are ignored.
+func ParseWordList(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		rule := Rule{Term: fields[0], Action: ActionMask}
+		if len(fields) > 1 {
+			rule.Action = Action(fields[1])
+		}
+
+		if len(fields) > 2 || !rule.Action.Valid() {
+			return nil, fmt.Errorf("line %d: expected \"term [mask|flag|reject]\"", line)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory is a Limiter that keeps buckets in process memory. It is only
+// correct when a single instance serves all traffic.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+const sweepInterval = time.Minute
+
+func (m *Memory) Allow(ctx context.Context, policy Policy, key string) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	id := policy.Name + ":" + key
+	b, ok := m.buckets[id]
+	if !ok {
+		b = &bucket{tokens: float64(policy.Burst), updated: now}
+		m.buckets[id] = b
+	}
+
+	return b.take(policy, now), nil
+}
+
+// sweep drops buckets that have refilled completely, since forgetting them
+// changes nothing.
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+
+	for id, b := range m.buckets {
+		if now.After(b.full) {
+			delete(m.buckets, id)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryAllow(t *testing.T) {
+	policy := Policy{Name: "login", Limit: 1, Per: time.Second, Burst: 3}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewMemory()
+	limiter.now = func() time.Time { return now }
+
+	tests := []struct {
+		name            string
+		key             string
+		advance         time.Duration
+		expectedAllowed bool
+		expectedRetry   time.Duration
+	}{
+		{name: "first request", key: "1.2.3.4", expectedAllowed: true},
+		{name: "second request", key: "1.2.3.4", expectedAllowed: true},
+		{name: "third request", key: "1.2.3.4", expectedAllowed: true},
+		{name: "burst exhausted", key: "1.2.3.4", expectedAllowed: false, expectedRetry: time.Second},
+		{name: "other key has its own bucket", key: "5.6.7.8", expectedAllowed: true},
+		{name: "partially refilled", key: "1.2.3.4", advance: 500 * time.Millisecond, expectedAllowed: false, expectedRetry: 500 * time.Millisecond},
+		{name: "refilled one token", key: "1.2.3.4", advance: 500 * time.Millisecond, expectedAllowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+
+			result, err := limiter.Allow(context.Background(), policy, tt.key)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Allowed != tt.expectedAllowed {
+				t.Errorf("got allowed %v, want %v", result.Allowed, tt.expectedAllowed)
+			}
+
+			if result.RetryAfter != tt.expectedRetry {
+				t.Errorf("got retry after %v, want %v", result.RetryAfter, tt.expectedRetry)
+			}
+		})
+	}
+}
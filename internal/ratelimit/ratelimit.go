@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Policy describes a token bucket: it holds at most Burst tokens and
+// refills at Limit tokens every Per.
+type Policy struct {
+	Name  string
+	Limit int
+	Per   time.Duration
+	Burst int
+}
+
+func (p Policy) ratePerSecond() float64 {
+	return float64(p.Limit) / p.Per.Seconds()
+}
+
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Limiter takes one token for key under policy. Implementations must be
+// safe for concurrent use; a shared store such as Postgres lets several
+// instances enforce the same limits.
+type Limiter interface {
+	Allow(ctx context.Context, policy Policy, key string) (Result, error)
+}
+
+// bucket is the state a Limiter keeps per policy and key.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket will have refilled to Burst, after which it
+	// is indistinguishable from a new one and can be forgotten.
+	full time.Time
+}
+
+// take refills b for the time elapsed since its last update and tries to
+// consume one token.
+func (b *bucket) take(policy Policy, now time.Time) Result {
+	rate := policy.ratePerSecond()
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(policy.Burst), b.tokens+elapsed*rate)
+		b.updated = now
+	}
+
+	result := Result{Allowed: false, RetryAfter: secondsToDuration((1 - b.tokens) / rate)}
+	if b.tokens >= 1 {
+		b.tokens--
+		result = Result{Allowed: true, Remaining: int(b.tokens)}
+	}
+
+	b.full = now.Add(secondsToDuration((float64(policy.Burst) - b.tokens) / rate))
+	return result
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,95 @@
+// Package search turns user supplied search strings into Postgres tsquery
+// expressions.
+package search
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+var ErrEmptyQuery = errors.New("query has no searchable terms")
+
+// ParseQuery converts q into to_tsquery syntax. Terms are ANDed together,
+// "double quoted" words must appear next to each other, a trailing * makes
+// a term match by prefix and a leading - excludes a term or phrase.
+//
+// Only letters and digits make it into the result, so the output is always
+// a valid tsquery no matter what the user typed.
+func ParseQuery(q string) (string, error) {
+	var clauses []string
+	positive := false
+
+	runes := []rune(q)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		negate := false
+		if runes[i] == '-' {
+			negate = true
+			i++
+		}
+
+		var term []rune
+		if i < len(runes) && runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			term = runes[i+1 : end]
+			i = end + 1
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '"' {
+				end++
+			}
+			term = runes[i:end]
+			i = end
+		}
+
+		clause := phrase(string(term))
+		if clause == "" {
+			continue
+		}
+
+		if negate {
+			clauses = append(clauses, "!("+clause+")")
+			continue
+		}
+		clauses = append(clauses, clause)
+		positive = true
+	}
+
+	// A query made only of exclusions would match nearly every chirp.
+	if !positive {
+		return "", ErrEmptyQuery
+	}
+
+	return strings.Join(clauses, " & "), nil
+}
+
+// phrase joins the words of term with the followed-by operator. A trailing
+// * turns the last word into a prefix match.
+func phrase(term string) string {
+	prefix := strings.HasSuffix(term, "*")
+
+	words := strings.FieldsFunc(term, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+
+	lexemes := make([]string, len(words))
+	for i, word := range words {
+		lexemes[i] = "'" + strings.ToLower(word) + "'"
+	}
+	if prefix {
+		lexemes[len(lexemes)-1] += ":*"
+	}
+
+	return strings.Join(lexemes, " <-> ")
+}
@@ -0,0 +1,73 @@
+package search
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		expected      string
+		expectedError error
+	}{
+		{
+			name:     "single word",
+			query:    "Chirpy",
+			expected: "'chirpy'",
+		},
+		{
+			name:     "words are anded",
+			query:    "  hello   world ",
+			expected: "'hello' & 'world'",
+		},
+		{
+			name:     "phrase",
+			query:    `"big red dog" bark`,
+			expected: "'big' <-> 'red' <-> 'dog' & 'bark'",
+		},
+		{
+			name:     "prefix",
+			query:    "chir* boot",
+			expected: "'chir':* & 'boot'",
+		},
+		{
+			name:     "excluded term",
+			query:    `dogs -cats -"hot dogs"`,
+			expected: "'dogs' & !('cats') & !('hot' <-> 'dogs')",
+		},
+		{
+			name:     "operators are stripped",
+			query:    "a&b | !c ('d':*)",
+			expected: "'a' <-> 'b' & 'c' & 'd'",
+		},
+		{
+			name:     "unterminated phrase",
+			query:    `"hello there`,
+			expected: "'hello' <-> 'there'",
+		},
+		{
+			name:          "only exclusions",
+			query:         "-cats -dogs",
+			expectedError: ErrEmptyQuery,
+		},
+		{
+			name:          "no words",
+			query:         `!! "" *`,
+			expectedError: ErrEmptyQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQuery(tt.query)
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("ParseQuery() error = %v, want %v", err, tt.expectedError)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseQuery() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -2,16 +2,20 @@ package main
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 )
 
 func respondWithError(w http.ResponseWriter, code int, msg string, err error) {
-	if err != nil {
-		log.Println(err)
+	logger := slog.Default()
+	if rec, ok := w.(*responseRecorder); ok && rec.info != nil {
+		logger = logger.With("request_id", rec.info.id)
 	}
+
 	if code > 499 {
-		log.Printf("Responding with 5XX error: %s", msg)
+		logger.Error("Responding with 5XX error", "status", code, "msg", msg, "error", err)
+	} else if err != nil {
+		logger.Info("Responding with error", "status", code, "msg", msg, "error", err)
 	}
 	type errorResponse struct {
 		Error string `json:"error"`
@@ -25,7 +29,7 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	dat, err := json.Marshal(payload)
 	if err != nil {
-		log.Printf("Error marshalling JSON: %s", err)
+		slog.Error("Error marshalling JSON", "error", err)
 		w.WriteHeader(500)
 		return
 	}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/RafaelTauschek/http-server/internal/auth"
+	"github.com/RafaelTauschek/http-server/internal/config"
+)
+
+// loadKeyring signs with the configured key file, falling back to the
+// SECRET HMAC key. SECRET keeps verifying either way so tokens issued
+// before a switch to asymmetric keys stay valid until they expire.
+func loadKeyring(cfg config.JWT, secret string) (*auth.Keyring, error) {
+	hmacKey := auth.NewHMACKey([]byte(secret))
+	signing := hmacKey
+	verification := []auth.Key{hmacKey}
+
+	if cfg.SigningKeyFile != "" {
+		key, err := readKeyFile(cfg.SigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		signing = key
+	}
+
+	for _, path := range cfg.VerificationKeyFiles {
+		key, err := readKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		verification = append(verification, key)
+	}
+
+	return auth.NewKeyring(signing, verification...)
+}
+
+func readKeyFile(path string) (auth.Key, error) {
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return auth.Key{}, err
+	}
+
+	key, err := auth.ParseKeyPEM(dat)
+	if err != nil {
+		return auth.Key{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return key, nil
+}
+
+func (cfg *apiConfig) handlerJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	respondWithJSON(w, http.StatusOK, cfg.keys.JWKS())
+}
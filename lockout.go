@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RafaelTauschek/http-server/internal/auth"
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	accountLockoutThreshold = 5
+	ipLockoutThreshold      = 20
+	baseLockout             = time.Minute
+	maxLockout              = time.Hour
+)
+
+// dummyPasswordHash is compared against when the email is unknown, so a
+// failed login costs the same bcrypt work whether or not the account exists.
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, err := auth.HashPassword("chirpy-dummy-password")
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+func emailLockoutKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipLockoutKey(ip string) string {
+	return "ip:" + ip
+}
+
+func lockoutDuration(failures, threshold int) time.Duration {
+	if failures < threshold {
+		return 0
+	}
+
+	d := baseLockout * time.Duration(math.Pow(2, float64(failures-threshold)))
+	if d > maxLockout || d <= 0 {
+		return maxLockout
+	}
+	return d
+}
+
+func (cfg *apiConfig) loginLockedFor(ctx context.Context, email, ip string) (time.Duration, error) {
+	lockouts, err := cfg.db.GetActiveLockouts(ctx, []string{emailLockoutKey(email), ipLockoutKey(ip)})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(lockouts) == 0 {
+		return 0, nil
+	}
+
+	// Ordered by locked_until, so the first one lasts the longest.
+	return time.Until(lockouts[0].LockedUntil.Time), nil
+}
+
+func (cfg *apiConfig) recordLoginFailure(r *http.Request, email, ip string, userID uuid.NullUUID) {
+	cfg.recordLoginAttempt(r, email, ip, userID, false)
+
+	failures := []struct {
+		key       string
+		threshold int
+	}{
+		{emailLockoutKey(email), accountLockoutThreshold},
+		{ipLockoutKey(ip), ipLockoutThreshold},
+	}
+
+	for _, f := range failures {
+		lockout, err := cfg.db.RecordLoginFailure(r.Context(), f.key)
+		if err != nil {
+			slog.Error("Couldn't record login failure", "key", f.key, "error", err)
+			continue
+		}
+
+		d := lockoutDuration(int(lockout.Failures), f.threshold)
+		if d == 0 {
+			continue
+		}
+
+		err = cfg.db.LockLogin(r.Context(), database.LockLoginParams{
+			Key:         f.key,
+			LockSeconds: int32(d.Seconds()),
+		})
+		if err != nil {
+			slog.Error("Couldn't lock login", "key", f.key, "error", err)
+		}
+	}
+}
+
+func (cfg *apiConfig) recordLoginSuccess(r *http.Request, email, ip string, userID uuid.UUID) {
+	cfg.recordLoginAttempt(r, email, ip, uuid.NullUUID{UUID: userID, Valid: true}, true)
+
+	_, err := cfg.db.ClearLockout(r.Context(), emailLockoutKey(email))
+	if err != nil {
+		slog.Error("Couldn't clear lockout", "error", err)
+	}
+}
+
+func (cfg *apiConfig) recordLoginAttempt(r *http.Request, email, ip string, userID uuid.NullUUID, succeeded bool) {
+	err := cfg.db.CreateLoginAttempt(r.Context(), database.CreateLoginAttemptParams{
+		Email:     email,
+		Ip:        ip,
+		UserAgent: r.UserAgent(),
+		UserID:    userID,
+		Succeeded: succeeded,
+	})
+	if err != nil {
+		slog.Error("Couldn't record login attempt", "error", err)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		expected time.Duration
+	}{
+		{
+			name:     "below threshold",
+			failures: 4,
+			expected: 0,
+		},
+		{
+			name:     "at threshold",
+			failures: 5,
+			expected: baseLockout,
+		},
+		{
+			name:     "doubles past threshold",
+			failures: 7,
+			expected: 4 * baseLockout,
+		},
+		{
+			name:     "capped",
+			failures: 20,
+			expected: maxLockout,
+		},
+		{
+			name:     "overflow is capped",
+			failures: 200,
+			expected: maxLockout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lockoutDuration(tt.failures, 5)
+			if got != tt.expected {
+				t.Errorf("got %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
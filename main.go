@@ -1,70 +1,202 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
 	"sync/atomic"
+	"syscall"
+	"time"
 
+	"github.com/RafaelTauschek/http-server/internal/auth"
+	"github.com/RafaelTauschek/http-server/internal/blob"
+	"github.com/RafaelTauschek/http-server/internal/config"
 	"github.com/RafaelTauschek/http-server/internal/database"
-	"github.com/joho/godotenv"
+	"github.com/RafaelTauschek/http-server/internal/moderation"
+	"github.com/RafaelTauschek/http-server/internal/ratelimit"
 	_ "github.com/lib/pq"
 )
 
 type apiConfig struct {
 	fileserverHits atomic.Int32
 	db             *database.Queries
+	conn           *sql.DB
+	metrics        *serverMetrics
 	platform       string
-	secret         string
+	keys           *auth.Keyring
+	accessTokenTTL time.Duration
 	apikey         string
+	wordListRules  []moderation.Rule
+	moderator      atomic.Pointer[moderation.Pipeline]
+	restoreWindow  time.Duration
+	purgeAfter     time.Duration
+	blobs          blob.Store
+	maxUploadBytes int64
+
+	limiter           ratelimit.Limiter
+	trustProxyHeaders bool
 }
 
 func main() {
 	mux := http.NewServeMux()
-	apiCfg := apiConfig{}
+	apiCfg := apiConfig{
+		metrics: newServerMetrics(),
+		limiter: ratelimit.NewMemory(),
+	}
+
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := sql.Open("postgres", cfg.DBURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	apiCfg.conn = db
+	apiCfg.db = database.New(database.Instrument(db, apiCfg.metrics.observeQuery))
+
+	apiCfg.platform = cfg.Platform
+	apiCfg.keys, err = loadKeyring(cfg.JWT, cfg.Secret)
+	if err != nil {
+		log.Fatal(err)
+	}
+	apiCfg.accessTokenTTL = cfg.JWT.AccessTokenTTL
+	apiCfg.apikey = cfg.PolkaKey
+	apiCfg.trustProxyHeaders = cfg.Server.TrustProxyHeaders
+	apiCfg.restoreWindow = cfg.Deletion.RestoreWindow
+	apiCfg.purgeAfter = cfg.Deletion.PurgeAfter
+	apiCfg.maxUploadBytes = cfg.Media.MaxUploadBytes
+
+	apiCfg.blobs, err = blob.NewFS(cfg.Media.Dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var adminEmails []string
+	for _, email := range cfg.AdminEmails {
+		adminEmails = append(adminEmails, strings.ToLower(email))
+	}
 
-	godotenv.Load()
-	dbURL := os.Getenv("DB_URL")
-	apiCfg.platform = os.Getenv("PLATFORM")
-	db, err := sql.Open("postgres", dbURL)
+	apiCfg.wordListRules, err = loadWordListFile(cfg.WordListFile)
 	if err != nil {
-		log.Fatal()
+		log.Fatal(err)
 	}
-	apiCfg.db = database.New(db)
 
-	apiCfg.secret = os.Getenv("SECRET")
-	apiCfg.apikey = os.Getenv("POLKA_KEY")
+	err = apiCfg.reloadModeration(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Only existing accounts are promoted. Emails aren't verified, so
+	// granting the role at signup would hand it to whoever registers first.
+	if len(adminEmails) > 0 {
+		promoted, err := apiCfg.db.PromoteAdmins(context.Background(), adminEmails)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if promoted > 0 {
+			slog.Info("Promoted users to admin", "count", promoted)
+		}
+	}
 
 	fsHandler := apiCfg.middlewareMetricsInc(http.StripPrefix("/app", http.FileServer(http.Dir("."))))
 	mux.Handle("/app/", fsHandler)
 	mux.HandleFunc("GET /api/healthz", handlerReadiness)
+	mux.HandleFunc("GET /.well-known/jwks.json", apiCfg.handlerJWKS)
+	mux.HandleFunc("GET /metrics", apiCfg.middlewareScrapeToken(cfg.MetricsToken, apiCfg.metrics.registry.Handler().ServeHTTP))
 
-	mux.HandleFunc("GET /admin/metrics", apiCfg.handlerMetrics)
-	mux.HandleFunc("POST /admin/reset", apiCfg.resetHandler)
+	mux.HandleFunc("GET /admin/metrics", apiCfg.middlewareRequire(permViewMetrics, apiCfg.handlerMetrics))
+	mux.HandleFunc("POST /admin/reset", apiCfg.middlewareRequire(permResetData, apiCfg.resetHandler))
+	mux.HandleFunc("GET /admin/lockouts", apiCfg.middlewareRequire(permManageLockouts, apiCfg.handlerListLockouts))
+	mux.HandleFunc("DELETE /admin/lockouts/{key}", apiCfg.middlewareRequire(permManageLockouts, apiCfg.handlerClearLockout))
+	mux.HandleFunc("PUT /admin/users/{userID}/role", apiCfg.middlewareRequire(permManageRoles, apiCfg.handlerSetUserRole))
+	mux.HandleFunc("POST /admin/users/{userID}/restore", apiCfg.middlewareRequire(permManageUsers, apiCfg.handlerRestoreUser))
+	mux.HandleFunc("POST /admin/purge", apiCfg.middlewareRequire(permManageUsers, apiCfg.handlerPurge))
 
-	mux.HandleFunc("POST /api/login", apiCfg.handlerLogin)
+	mux.HandleFunc("GET /admin/moderation/rules", apiCfg.middlewareRequire(permManageRules, apiCfg.handlerListModerationRules))
+	mux.HandleFunc("POST /admin/moderation/rules", apiCfg.middlewareRequire(permManageRules, apiCfg.handlerCreateModerationRule))
+	mux.HandleFunc("PUT /admin/moderation/rules/{ruleID}", apiCfg.middlewareRequire(permManageRules, apiCfg.handlerUpdateModerationRule))
+	mux.HandleFunc("DELETE /admin/moderation/rules/{ruleID}", apiCfg.middlewareRequire(permManageRules, apiCfg.handlerDeleteModerationRule))
+
+	mux.HandleFunc("GET /admin/reports", apiCfg.middlewareRequire(permModerateChirps, apiCfg.handlerReportQueue))
+	mux.HandleFunc("POST /admin/reports/chirps/{chirpID}/resolve", apiCfg.middlewareRequire(permModerateChirps, apiCfg.handlerResolveReports))
+
+	mux.HandleFunc("POST /api/login", apiCfg.middlewareRateLimit(loginLimits, apiCfg.handlerLogin))
 	mux.HandleFunc("POST /api/refresh", apiCfg.handlerRefreshToken)
 	mux.HandleFunc("POST /api/revoke", apiCfg.handlerRevokeToken)
 
-	mux.HandleFunc("POST /api/users", apiCfg.handlerCreateUser)
-	mux.HandleFunc("PUT /api/users", apiCfg.handlerUpdateUser)
+	mux.HandleFunc("GET /api/sessions", apiCfg.middlewareAuth(authRequired, apiCfg.handlerListSessions))
+	mux.HandleFunc("DELETE /api/sessions/{id}", apiCfg.middlewareAuth(authRequired, apiCfg.handlerRevokeSession))
+	mux.HandleFunc("POST /api/sessions/revoke-others", apiCfg.handlerRevokeOtherSessions)
+
+	mux.HandleFunc("POST /api/users", apiCfg.middlewareRateLimit(createUserLimits, apiCfg.handlerCreateUser))
+	mux.HandleFunc("PUT /api/users", apiCfg.middlewareAuth(authRequired, apiCfg.handlerUpdateUser))
+	mux.HandleFunc("DELETE /api/users", apiCfg.middlewareAuth(authRequired, apiCfg.handlerDeleteUser))
 
-	mux.HandleFunc("POST /api/chirps", apiCfg.handlerAddChirps)
-	mux.HandleFunc("GET /api/chirps", apiCfg.handlerGetChirps)
-	mux.HandleFunc("GET /api/chirps/{chirpID}", apiCfg.handlerGetChirp)
-	mux.HandleFunc("DELETE /api/chirps/{chirpID}", apiCfg.handlerDeleteChirp)
+	mux.HandleFunc("POST /api/chirps", apiCfg.middlewareAuth(authRequired, apiCfg.middlewareRateLimit(createChirpLimits, apiCfg.handlerAddChirps)))
+	mux.HandleFunc("GET /api/chirps", apiCfg.middlewareAuth(authOptional, apiCfg.handlerGetChirps))
+	mux.HandleFunc("GET /api/chirps/search", apiCfg.middlewareAuth(authOptional, apiCfg.handlerSearchChirps))
+	mux.HandleFunc("GET /api/hashtags/{tag}/chirps", apiCfg.middlewareAuth(authOptional, apiCfg.handlerHashtagChirps))
+	mux.HandleFunc("GET /api/users/{userID}/mentions", apiCfg.middlewareAuth(authOptional, apiCfg.handlerUserMentions))
+	mux.HandleFunc("POST /api/users/{userID}/follow", apiCfg.middlewareAuth(authRequired, apiCfg.handlerFollow))
+	mux.HandleFunc("DELETE /api/users/{userID}/follow", apiCfg.middlewareAuth(authRequired, apiCfg.handlerUnfollow))
+	mux.HandleFunc("GET /api/users/{userID}/followers", apiCfg.handlerListFollowers)
+	mux.HandleFunc("GET /api/users/{userID}/following", apiCfg.handlerListFollowing)
+	mux.HandleFunc("GET /api/timeline", apiCfg.middlewareAuth(authRequired, apiCfg.handlerTimeline))
+	mux.HandleFunc("GET /api/chirps/{chirpID}", apiCfg.middlewareAuth(authOptional, apiCfg.handlerGetChirp))
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}", apiCfg.middlewareAuth(authRequired, apiCfg.handlerDeleteChirp))
+	mux.HandleFunc("POST /api/chirps/{chirpID}/restore", apiCfg.middlewareAuth(authRequired, apiCfg.handlerRestoreChirp))
+	mux.HandleFunc("PUT /api/chirps/{chirpID}", apiCfg.middlewareAuth(authRequired, apiCfg.handlerUpdateChirp))
+	mux.HandleFunc("PATCH /api/chirps/{chirpID}", apiCfg.middlewareAuth(authRequired, apiCfg.handlerUpdateChirp))
+	mux.HandleFunc("GET /api/chirps/{chirpID}/revisions", apiCfg.middlewareAuth(authOptional, apiCfg.handlerListChirpRevisions))
+	mux.HandleFunc("GET /api/chirps/{chirpID}/thread", apiCfg.middlewareAuth(authOptional, apiCfg.handlerGetThread))
+	mux.HandleFunc("POST /api/chirps/{chirpID}/reports", apiCfg.middlewareAuth(authRequired, apiCfg.handlerReportChirp))
+	mux.HandleFunc("POST /api/chirps/{chirpID}/like", apiCfg.middlewareAuth(authRequired, apiCfg.handlerLikeChirp))
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/like", apiCfg.middlewareAuth(authRequired, apiCfg.handlerUnlikeChirp))
+	mux.HandleFunc("POST /api/chirps/{chirpID}/rechirp", apiCfg.middlewareAuth(authRequired, apiCfg.handlerRechirp))
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/rechirp", apiCfg.middlewareAuth(authRequired, apiCfg.handlerUndoRechirp))
+	mux.HandleFunc("POST /api/chirps/{chirpID}/attachments", apiCfg.middlewareAuth(authRequired, apiCfg.handlerUploadAttachment))
+	mux.HandleFunc("GET /api/attachments/{attachmentID}", apiCfg.middlewareAuth(authOptional, apiCfg.handlerGetAttachment))
+	mux.HandleFunc("GET /api/attachments/{attachmentID}/thumbnail", apiCfg.middlewareAuth(authOptional, apiCfg.handlerGetAttachmentThumbnail))
+	mux.HandleFunc("DELETE /api/attachments/{attachmentID}", apiCfg.middlewareAuth(authRequired, apiCfg.handlerDeleteAttachment))
 
 	mux.HandleFunc("POST /api/polka/webhooks", apiCfg.handlerWebhook)
 
+	handler := chain(mux,
+		middlewareRequestInfo(mux),
+		middlewareLogging,
+		apiCfg.metrics.middlewareInstrument,
+	)
+
 	server := &http.Server{
-		Addr:    ":8080",
-		Handler: mux,
+		Addr:              cfg.Server.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
 	}
 
-	err = server.ListenAndServe()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.Deletion.PurgeInterval > 0 {
+		go apiCfg.runPurgeJob(ctx, cfg.Deletion.PurgeInterval)
+	}
+	if cfg.Subscriptions.ExpiryInterval > 0 {
+		go apiCfg.runSubscriptionExpiryJob(ctx, cfg.Subscriptions.ExpiryInterval)
+	}
+
+	err = runServer(ctx, server, db, cfg.Server.ShutdownTimeout)
 	if err != nil {
 		log.Fatal(err)
 	}
-
 }
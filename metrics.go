@@ -1,10 +1,87 @@
 package main
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/RafaelTauschek/http-server/internal/auth"
+	"github.com/RafaelTauschek/http-server/internal/metrics"
 )
 
+type serverMetrics struct {
+	registry        *metrics.Registry
+	requests        *metrics.CounterVec
+	requestDuration *metrics.HistogramVec
+	inFlight        *metrics.GaugeVec
+	queryDuration   *metrics.HistogramVec
+	queryErrors     *metrics.CounterVec
+}
+
+func newServerMetrics() *serverMetrics {
+	registry := metrics.NewRegistry()
+
+	return &serverMetrics{
+		registry: registry,
+		requests: registry.NewCounterVec(
+			"chirpy_http_requests_total",
+			"Number of HTTP requests handled, by route and status code.",
+			"route", "code",
+		),
+		requestDuration: registry.NewHistogramVec(
+			"chirpy_http_request_duration_seconds",
+			"Time spent handling HTTP requests, by route and status code.",
+			metrics.DefaultBuckets,
+			"route", "code",
+		),
+		inFlight: registry.NewGaugeVec(
+			"chirpy_http_requests_in_flight",
+			"Number of HTTP requests currently being handled, by route.",
+			"route",
+		),
+		queryDuration: registry.NewHistogramVec(
+			"chirpy_db_query_duration_seconds",
+			"Time spent running database queries, by sqlc query name.",
+			metrics.DefaultBuckets,
+			"query",
+		),
+		queryErrors: registry.NewCounterVec(
+			"chirpy_db_query_errors_total",
+			"Number of database queries that returned an error, by sqlc query name.",
+			"query",
+		),
+	}
+}
+
+func (m *serverMetrics) observeQuery(query string, duration time.Duration, err error) {
+	m.queryDuration.WithLabelValues(query).Observe(duration.Seconds())
+	if err != nil {
+		m.queryErrors.WithLabelValues(query).Inc()
+	}
+}
+
+// middlewareInstrument labels requests by route pattern rather than path
+// to keep the number of series bounded.
+func (m *serverMetrics) middlewareInstrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := requestInfoFrom(r.Context()).route
+
+		inFlight := m.inFlight.WithLabelValues(route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		rec := recorderFor(w)
+		next.ServeHTTP(rec, r)
+
+		code := strconv.Itoa(rec.status)
+		m.requests.WithLabelValues(route, code).Inc()
+		m.requestDuration.WithLabelValues(route, code).Observe(time.Since(start).Seconds())
+	})
+}
+
 func (cfg *apiConfig) handlerMetrics(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Content-Type", "text/html")
 	w.WriteHeader(http.StatusOK)
@@ -26,3 +103,17 @@ func (cfg *apiConfig) middlewareMetricsInc(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// middlewareScrapeToken lets scrapers in with the configured token and
+// everyone else through the permViewMetrics role check.
+func (cfg *apiConfig) middlewareScrapeToken(token string, next http.HandlerFunc) http.HandlerFunc {
+	requireRole := cfg.middlewareRequire(permViewMetrics, next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		got, err := auth.GetBearerToken(r.Header)
+		if err == nil && token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+			next(w, r)
+			return
+		}
+		requireRole(w, r)
+	}
+}
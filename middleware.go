@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type middleware func(http.Handler) http.Handler
+
+// chain applies middlewares so the first one listed is the outermost.
+func chain(h http.Handler, middlewares ...middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+type requestInfo struct {
+	id     string
+	route  string
+	userID uuid.UUID
+}
+
+type requestInfoKey struct{}
+
+func requestInfoFrom(ctx context.Context) *requestInfo {
+	info, ok := ctx.Value(requestInfoKey{}).(*requestInfo)
+	if !ok {
+		return &requestInfo{}
+	}
+	return info
+}
+
+func setRequestUser(r *http.Request, userID uuid.UUID) {
+	requestInfoFrom(r.Context()).userID = userID
+}
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+func middlewareRequestInfo(mux *http.ServeMux) middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get("X-Request-ID")
+			if !validRequestID.MatchString(id) {
+				id = uuid.NewString()
+			}
+
+			_, route := mux.Handler(r)
+			if route == "" {
+				route = "unmatched"
+			}
+
+			info := &requestInfo{id: id, route: route}
+			w.Header().Set("X-Request-ID", id)
+
+			rec := recorderFor(w)
+			rec.info = info
+
+			next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info)))
+		})
+	}
+}
+
+func middlewareLogging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := recorderFor(w)
+		next.ServeHTTP(rec, r)
+
+		info := requestInfoFrom(r.Context())
+		attrs := []any{
+			"request_id", info.id,
+			"method", r.Method,
+			"route", info.route,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"bytes", rec.bytes,
+			"duration", time.Since(start),
+		}
+		if info.userID != uuid.Nil {
+			attrs = append(attrs, "user_id", info.userID)
+		}
+
+		level := slog.LevelInfo
+		if rec.status > 499 {
+			level = slog.LevelError
+		}
+		slog.Log(r.Context(), level, "request", attrs...)
+	})
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/RafaelTauschek/http-server/internal/auth"
+	"github.com/RafaelTauschek/http-server/internal/database"
+)
+
+type authRequirement int
+
+const (
+	// authOptional loads the user when a token is sent but lets anonymous
+	// requests through. A token that is sent but invalid is still rejected.
+	authOptional authRequirement = iota
+	authRequired
+	authChirpyRed
+)
+
+type userContextKey struct{}
+
+// userFromContext returns the user authenticated by middlewareAuth.
+func userFromContext(ctx context.Context) (database.User, bool) {
+	user, ok := ctx.Value(userContextKey{}).(database.User)
+	return user, ok
+}
+
+// mustUser is for handlers behind authRequired or stricter, where the
+// middleware guarantees a user is present.
+func mustUser(ctx context.Context) database.User {
+	user, ok := userFromContext(ctx)
+	if !ok {
+		panic("mustUser called on a route without required authentication")
+	}
+	return user
+}
+
+func (cfg *apiConfig) middlewareAuth(requirement authRequirement, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if requirement == authOptional && r.Header.Get("Authorization") == "" {
+			next(w, r)
+			return
+		}
+
+		token, err := auth.GetBearerToken(r.Header)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "Couldn't get bearer token", err)
+			return
+		}
+
+		userID, err := auth.ValidateJWT(token, cfg.keys)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+			return
+		}
+
+		user, err := cfg.db.GetUserByID(r.Context(), userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusUnauthorized, "User no longer exists", err)
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+			return
+		}
+		setRequestUser(r, user.ID)
+
+		if requirement == authChirpyRed && !user.IsChirpyRed {
+			respondWithError(w, http.StatusForbidden, "Chirpy Red is required", nil)
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), userContextKey{}, user)))
+	}
+}
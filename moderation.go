@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/RafaelTauschek/http-server/internal/moderation"
+	"github.com/google/uuid"
+)
+
+// ModerationReport tells the author which rules fired on their chirp.
+type ModerationReport struct {
+	Flagged  bool               `json:"flagged"`
+	Rejected bool               `json:"rejected"`
+	Matches  []moderation.Match `json:"matches"`
+}
+
+func newModerationReport(result moderation.Result) *ModerationReport {
+	if len(result.Matches) == 0 {
+		return nil
+	}
+	return &ModerationReport{
+		Flagged:  result.Flagged,
+		Rejected: result.Rejected,
+		Matches:  result.Matches,
+	}
+}
+
+func flaggedRules(result moderation.Result) []string {
+	var rules []string
+	for _, m := range result.Matches {
+		if m.Action == moderation.ActionFlag {
+			rules = append(rules, m.Rule)
+		}
+	}
+	return rules
+}
+
+// reportFlaggedChirp puts a chirp in the moderation queue when a flag rule
+// fired on it. Automatic reports have no reporter.
+func reportFlaggedChirp(ctx context.Context, q *database.Queries, chirpID uuid.UUID, result moderation.Result) error {
+	if !result.Flagged {
+		return nil
+	}
+
+	_, err := q.CreateReport(ctx, database.CreateReportParams{
+		ChirpID: chirpID,
+		Reason:  "automatic: " + strings.Join(flaggedRules(result), ", "),
+	})
+	return err
+}
+
+func loadWordListFile(path string) ([]moderation.Rule, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return moderation.ParseWordList(f)
+}
+
+// reloadModeration rebuilds the pipeline from the word list file and the
+// rules stored in the database. It runs at startup and after every change
+// made through the admin API.
+func (cfg *apiConfig) reloadModeration(ctx context.Context) error {
+	data, err := cfg.db.ListModerationRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	rules := append([]moderation.Rule(nil), cfg.wordListRules...)
+	for _, rule := range data {
+		rules = append(rules, moderation.Rule{
+			ID:     rule.ID.String(),
+			Term:   rule.Term,
+			Action: moderation.Action(rule.Action),
+		})
+	}
+
+	cfg.moderator.Store(moderation.NewPipeline(moderation.NewWordList(rules)))
+	return nil
+}
+
+func (cfg *apiConfig) moderate(text string) moderation.Result {
+	return cfg.moderator.Load().Moderate(text)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// cursor is the sort value and id of the last row on a page.
+type cursor struct {
+	Time time.Time
+	ID   uuid.UUID
+}
+
+func encodeCursor(c cursor) string {
+	raw := c.Time.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, errors.New("malformed cursor")
+	}
+
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return cursor{}, errors.New("malformed cursor")
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return cursor{}, errors.New("malformed cursor")
+	}
+
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return cursor{}, errors.New("malformed cursor")
+	}
+
+	return cursor{Time: t, ID: parsedID}, nil
+}
+
+// encodeOffsetCursor is for listings that can't be paged by key, such as
+// ranked search results.
+func encodeOffsetCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeOffsetCursor(s string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, errors.New("malformed cursor")
+	}
+
+	// The next page's offset must still fit the query's int32 OFFSET.
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 || offset > math.MaxInt32-maxPageLimit {
+		return 0, errors.New("malformed cursor")
+	}
+
+	return offset, nil
+}
+
+func parseLimit(s string) (int, error) {
+	if s == "" {
+		return defaultPageLimit, nil
+	}
+
+	limit, err := strconv.Atoi(s)
+	if err != nil || limit < 1 {
+		return 0, errors.New("limit must be a positive integer")
+	}
+
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	return limit, nil
+}
+
+func setNextLink(w http.ResponseWriter, r *http.Request, nextCursor string) {
+	if nextCursor == "" {
+		return
+	}
+
+	query := r.URL.Query()
+	query.Set("cursor", nextCursor)
+
+	next := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.String()))
+}
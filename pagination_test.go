@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	valid := cursor{
+		Time: time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC),
+		ID:   uuid.New(),
+	}
+
+	tests := []struct {
+		name          string
+		input         string
+		expected      cursor
+		expectedError bool
+	}{
+		{
+			name:     "round trip",
+			input:    encodeCursor(valid),
+			expected: valid,
+		},
+		{
+			name:          "not base64",
+			input:         "%%%",
+			expectedError: true,
+		},
+		{
+			name:          "missing separator",
+			input:         base64.RawURLEncoding.EncodeToString([]byte("2024-05-01T12:30:00Z")),
+			expectedError: true,
+		},
+		{
+			name:          "invalid time",
+			input:         base64.RawURLEncoding.EncodeToString([]byte("yesterday|" + valid.ID.String())),
+			expectedError: true,
+		},
+		{
+			name:          "invalid id",
+			input:         base64.RawURLEncoding.EncodeToString([]byte("2024-05-01T12:30:00Z|42")),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCursor(tt.input)
+
+			if tt.expectedError && err == nil {
+				t.Error("expected error but got none")
+			}
+
+			if !tt.expectedError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !got.Time.Equal(tt.expected.Time) || got.ID != tt.expected.ID {
+				t.Errorf("got %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeOffsetCursor(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expected      int
+		expectedError bool
+	}{
+		{
+			name:     "round trip",
+			input:    encodeOffsetCursor(40),
+			expected: 40,
+		},
+		{
+			name:          "negative offset",
+			input:         base64.RawURLEncoding.EncodeToString([]byte("-1")),
+			expectedError: true,
+		},
+		{
+			name:          "offset out of range",
+			input:         base64.RawURLEncoding.EncodeToString([]byte("2147483648")),
+			expectedError: true,
+		},
+		{
+			name:          "not a number",
+			input:         base64.RawURLEncoding.EncodeToString([]byte("ten")),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeOffsetCursor(tt.input)
+
+			if tt.expectedError && err == nil {
+				t.Error("expected error but got none")
+			}
+
+			if !tt.expectedError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if got != tt.expected {
+				t.Errorf("got %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+)
+
+const (
+	roleUser      = "user"
+	roleModerator = "moderator"
+	roleAdmin     = "admin"
+)
+
+type permission string
+
+const (
+	permViewMetrics    permission = "metrics:view"
+	permResetData      permission = "data:reset"
+	permManageLockouts permission = "lockouts:manage"
+	permManageRoles    permission = "roles:manage"
+	permModerateChirps permission = "chirps:moderate"
+	permManageRules    permission = "moderation_rules:manage"
+	permManageUsers    permission = "users:manage"
+)
+
+var rolePermissions = map[string][]permission{
+	roleUser:      {},
+	roleModerator: {permModerateChirps},
+	roleAdmin: {
+		permViewMetrics,
+		permResetData,
+		permManageLockouts,
+		permManageRoles,
+		permModerateChirps,
+		permManageRules,
+		permManageUsers,
+	},
+}
+
+func isValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+func hasPermission(role string, perm permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// middlewareRequire authenticates the request and rejects users whose role
+// lacks perm. The role is read from the database rather than the token so
+// a demotion takes effect immediately.
+func (cfg *apiConfig) middlewareRequire(perm permission, next http.HandlerFunc) http.HandlerFunc {
+	return cfg.middlewareAuth(authRequired, func(w http.ResponseWriter, r *http.Request) {
+		user := mustUser(r.Context())
+		if !hasPermission(user.Role, perm) {
+			respondWithError(w, http.StatusForbidden, "Access not allowed", nil)
+			return
+		}
+		next(w, r)
+	})
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/RafaelTauschek/http-server/internal/ratelimit"
+)
+
+const (
+	planFree = "free"
+	planRed  = "red"
+)
+
+// plan mirrors a row of the plans table.
+type plan struct {
+	Name            string
+	MaxChirpLength  int
+	EditChirps      bool
+	RateLimitFactor int
+	UploadFactor    int
+}
+
+func planFromDB(p database.Plan) plan {
+	return plan{
+		Name:            p.Name,
+		MaxChirpLength:  int(p.MaxChirpLength),
+		EditChirps:      p.EditChirps,
+		RateLimitFactor: int(p.RateLimitFactor),
+		UploadFactor:    int(p.UploadFactor),
+	}
+}
+
+func planName(user database.User) string {
+	if user.IsChirpyRed {
+		return planRed
+	}
+	return planFree
+}
+
+func (cfg *apiConfig) planFor(ctx context.Context, user database.User) (plan, error) {
+	p, err := cfg.db.GetPlan(ctx, planName(user))
+	if err != nil {
+		return plan{}, err
+	}
+	return planFromDB(p), nil
+}
+
+// scaled names the bucket after the plan so a plan change starts afresh.
+func (p plan) scaled(policy ratelimit.Policy) ratelimit.Policy {
+	if p.RateLimitFactor == 1 {
+		return policy
+	}
+	policy.Name += "_" + p.Name
+	policy.Limit *= p.RateLimitFactor
+	policy.Burst *= p.RateLimitFactor
+	return policy
+}
+
+func (p plan) maxUploadBytes(base int64) int64 {
+	return base * int64(p.UploadFactor)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/RafaelTauschek/http-server/internal/ratelimit"
+)
+
+func TestPlanName(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     database.User
+		expected string
+	}{
+		{
+			name:     "regular user",
+			user:     database.User{},
+			expected: planFree,
+		},
+		{
+			name:     "chirpy red user",
+			user:     database.User{IsChirpyRed: true},
+			expected: planRed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := planName(tt.user); got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPlanScaled(t *testing.T) {
+	base := ratelimit.Policy{Name: "create_chirp_user", Limit: 20, Per: time.Minute, Burst: 10}
+
+	tests := []struct {
+		name     string
+		plan     plan
+		expected ratelimit.Policy
+	}{
+		{
+			name:     "factor of one keeps the policy",
+			plan:     plan{Name: planFree, RateLimitFactor: 1},
+			expected: base,
+		},
+		{
+			name:     "factor scales rate and burst in a separate bucket",
+			plan:     plan{Name: planRed, RateLimitFactor: 3},
+			expected: ratelimit.Policy{Name: "create_chirp_user_red", Limit: 60, Per: time.Minute, Burst: 30},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.plan.scaled(base); got != tt.expected {
+				t.Errorf("got %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPlanMaxUploadBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		factor   int
+		expected int64
+	}{
+		{
+			name:     "base limit",
+			factor:   1,
+			expected: 5 << 20,
+		},
+		{
+			name:     "multiplied limit",
+			factor:   4,
+			expected: 20 << 20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := plan{UploadFactor: tt.factor}
+			if got := p.maxUploadBytes(5 << 20); got != tt.expected {
+				t.Errorf("got %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type PurgeResult struct {
+	Users  int64 `json:"users"`
+	Chirps int64 `json:"chirps"`
+}
+
+// purgeDeleted removes users first so their chirps go with the cascade.
+// Reaction counts already exclude deleted users.
+func (cfg *apiConfig) purgeDeleted(ctx context.Context) (PurgeResult, error) {
+	seconds := int32(cfg.purgeAfter.Seconds())
+
+	attachments, err := cfg.db.ListPurgeableAttachments(ctx, seconds)
+	if err != nil {
+		return PurgeResult{}, err
+	}
+
+	users, err := cfg.db.PurgeDeletedUsers(ctx, seconds)
+	if err != nil {
+		return PurgeResult{}, err
+	}
+
+	chirps, err := cfg.db.PurgeDeletedChirps(ctx, seconds)
+	if err != nil {
+		return PurgeResult{Users: users}, err
+	}
+
+	for _, a := range attachments {
+		cfg.deleteBlobs(ctx, a.BlobKey, a.ThumbnailKey)
+	}
+
+	return PurgeResult{Users: users, Chirps: chirps}, nil
+}
+
+func (cfg *apiConfig) runPurgeJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		result, err := cfg.purgeDeleted(ctx)
+		if err != nil {
+			slog.Error("Couldn't purge deleted rows", "error", err)
+			continue
+		}
+		if result.Users > 0 || result.Chirps > 0 {
+			slog.Info("Purged deleted rows", "users", result.Users, "chirps", result.Chirps)
+		}
+	}
+}
+
+func (cfg *apiConfig) handlerPurge(w http.ResponseWriter, r *http.Request) {
+	result, err := cfg.purgeDeleted(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't purge deleted rows", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RafaelTauschek/http-server/internal/ratelimit"
+)
+
+// routeLimits may leave either policy nil. The user policy only applies
+// behind middlewareAuth.
+type routeLimits struct {
+	ip   *ratelimit.Policy
+	user *ratelimit.Policy
+}
+
+var (
+	loginLimits = routeLimits{
+		ip: &ratelimit.Policy{Name: "login_ip", Limit: 5, Per: time.Minute, Burst: 10},
+	}
+	createUserLimits = routeLimits{
+		ip: &ratelimit.Policy{Name: "create_user_ip", Limit: 10, Per: time.Hour, Burst: 5},
+	}
+	createChirpLimits = routeLimits{
+		ip:   &ratelimit.Policy{Name: "create_chirp_ip", Limit: 60, Per: time.Minute, Burst: 30},
+		user: &ratelimit.Policy{Name: "create_chirp_user", Limit: 20, Per: time.Minute, Burst: 10},
+	}
+)
+
+func (cfg *apiConfig) middlewareRateLimit(limits routeLimits, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if limits.ip != nil && !cfg.allow(w, r, *limits.ip, "ip:"+cfg.clientIP(r)) {
+			return
+		}
+
+		if user, ok := userFromContext(r.Context()); ok && limits.user != nil {
+			policy := *limits.user
+			userPlan, err := cfg.planFor(r.Context(), user)
+			if err != nil {
+				slog.Error("Couldn't load plan", "error", err)
+			} else {
+				policy = userPlan.scaled(policy)
+			}
+			if !cfg.allow(w, r, policy, "user:"+user.ID.String()) {
+				return
+			}
+		}
+
+		next(w, r)
+	}
+}
+
+// allow fails open when the limiter errors, so a backend outage doesn't
+// take the API down with it.
+func (cfg *apiConfig) allow(w http.ResponseWriter, r *http.Request, policy ratelimit.Policy, key string) bool {
+	result, err := cfg.limiter.Allow(r.Context(), policy, key)
+	if err != nil {
+		slog.Error("Rate limiter failed", "policy", policy.Name, "error", err)
+		return true
+	}
+
+	if result.Allowed {
+		return true
+	}
+
+	setRetryAfter(w, result.RetryAfter)
+	respondWithError(w, http.StatusTooManyRequests, "Too many requests", nil)
+	return false
+}
+
+func setRetryAfter(w http.ResponseWriter, d time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+}
+
+func (cfg *apiConfig) clientIP(r *http.Request) string {
+	// Only the last entry was added by our proxy. Everything before it
+	// comes from the client and can be forged.
+	if cfg.trustProxyHeaders {
+		if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			forwarded := values[len(values)-1]
+			last := strings.TrimSpace(forwarded[strings.LastIndex(forwarded, ",")+1:])
+			if last != "" {
+				return last
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
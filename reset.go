@@ -1,20 +1,23 @@
 package main
 
 import (
-	"context"
 	"errors"
 	"net/http"
 )
 
 func (cfg *apiConfig) resetHandler(w http.ResponseWriter, r *http.Request) {
+	// Wiping every user is never something production should allow, even
+	// for an admin.
 	if cfg.platform != "dev" {
 		respondWithError(w, http.StatusForbidden, "Access not allowed", errors.New("forbidden"))
+		return
 	}
-	cfg.fileserverHits.Store(0)
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Hits reset to 0"))
-	err := cfg.db.DeleteUsers(context.Background())
+	err := cfg.db.DeleteUsers(r.Context())
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't delete users form db", err)
+		return
 	}
+	cfg.fileserverHits.Store(0)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Hits reset to 0"))
 }
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// runServer serves until ctx is done, then drains in-flight requests for
+// at most shutdownTimeout.
+func runServer(ctx context.Context, server *http.Server, db *sql.DB, shutdownTimeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("Serving", "addr", server.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		db.Close()
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(shutdownCtx)
+	if closeErr := db.Close(); closeErr != nil {
+		slog.Error("Couldn't close database", "error", closeErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
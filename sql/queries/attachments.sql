@@ -0,0 +1,32 @@
+-- name: CreateAttachment :one
+INSERT INTO attachments (
+    id, created_at, chirp_id, content_type, size_bytes, width, height, blob_key,
+    thumbnail_content_type, thumbnail_width, thumbnail_height, thumbnail_key
+)
+VALUES ($1, NOW(), $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: CountChirpAttachments :one
+SELECT COUNT(*) FROM attachments
+WHERE chirp_id = $1;
+
+-- name: GetAttachment :one
+SELECT * FROM attachments
+WHERE id = $1;
+
+-- name: DeleteAttachment :exec
+DELETE FROM attachments
+WHERE id = $1;
+
+-- name: ListChirpAttachments :many
+SELECT * FROM attachments
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_id, created_at, id;
+
+-- name: ListPurgeableAttachments :many
+SELECT attachments.blob_key, attachments.thumbnail_key
+FROM attachments
+JOIN chirps ON chirps.id = attachments.chirp_id
+LEFT JOIN users ON users.id = chirps.user_id
+WHERE chirps.deleted_at < NOW() - sqlc.arg('purge_after_seconds')::integer * interval '1 second'
+OR users.deleted_at < NOW() - sqlc.arg('purge_after_seconds')::integer * interval '1 second';
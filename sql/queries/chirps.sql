@@ -1,29 +1,220 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_id)
 VALUES(
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
 RETURNING *;
 
 -- name: DeleteChirps :exec
 DELETE FROM chirps;
 
--- name: GetChrips :many
-SELECT * FROM chirps
-ORDER BY created_at ASC;
-
 -- name: GetChirpById :one
-SELECT * FROM chirps WHERE id = $1;
+SELECT * FROM chirps WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: DeleteChirp :execrows
+UPDATE chirps
+SET deleted_at = NOW(), deleted_by = $2
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreChirp :one
+UPDATE chirps
+SET deleted_at = NULL, deleted_by = NULL
+WHERE id = $1
+AND user_id = sqlc.arg('user_id')
+AND deleted_by = sqlc.arg('user_id')
+AND deleted_at > NOW() - sqlc.arg('restore_window_seconds')::integer * interval '1 second'
+RETURNING *;
+
+-- name: DeleteUserChirps :exec
+UPDATE chirps
+SET deleted_at = $2, deleted_by = user_id
+WHERE user_id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreUserChirps :exec
+UPDATE chirps
+SET deleted_at = NULL, deleted_by = NULL
+WHERE user_id = $1 AND deleted_at = $2;
 
--- name: DeleteChirp :exec
+-- name: PurgeDeletedChirps :execrows
 DELETE FROM chirps
-WHERE id = $1;
+WHERE deleted_at < NOW() - sqlc.arg('purge_after_seconds')::integer * interval '1 second';
 
--- name: GetChirpsByUser :many
+-- name: ListChirpsByCreatedAsc :many
 SELECT * FROM chirps
-WHERE user_id = $1
-ORDER BY created_at ASC;
+WHERE NOT hidden AND deleted_at IS NULL
+AND (COALESCE(cardinality(sqlc.arg('author_ids')::uuid[]), 0) = 0 OR user_id = ANY(sqlc.arg('author_ids')::uuid[]))
+AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at > sqlc.narg('created_after')::timestamp)
+AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before')::timestamp)
+AND (sqlc.narg('hashtag')::text IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_hashtags
+    WHERE chirp_hashtags.chirp_id = chirps.id AND chirp_hashtags.tag = lower(sqlc.narg('hashtag')::text)
+))
+AND (sqlc.narg('mention')::text IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_mentions
+    JOIN users ON users.id = chirp_mentions.user_id
+    WHERE chirp_mentions.chirp_id = chirps.id AND lower(users.handle) = lower(sqlc.narg('mention')::text)
+))
+AND (sqlc.narg('mentioned_user_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_mentions
+    WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = sqlc.narg('mentioned_user_id')::uuid
+))
+AND (sqlc.narg('timeline_of')::uuid IS NULL OR user_id = sqlc.narg('timeline_of')::uuid OR user_id IN (
+    SELECT followee_id FROM follows WHERE follower_id = sqlc.narg('timeline_of')::uuid
+))
+AND (
+    sqlc.narg('cursor_time')::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg('cursor_time')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListChirpsByCreatedDesc :many
+SELECT * FROM chirps
+WHERE NOT hidden AND deleted_at IS NULL
+AND (COALESCE(cardinality(sqlc.arg('author_ids')::uuid[]), 0) = 0 OR user_id = ANY(sqlc.arg('author_ids')::uuid[]))
+AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at > sqlc.narg('created_after')::timestamp)
+AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before')::timestamp)
+AND (sqlc.narg('hashtag')::text IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_hashtags
+    WHERE chirp_hashtags.chirp_id = chirps.id AND chirp_hashtags.tag = lower(sqlc.narg('hashtag')::text)
+))
+AND (sqlc.narg('mention')::text IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_mentions
+    JOIN users ON users.id = chirp_mentions.user_id
+    WHERE chirp_mentions.chirp_id = chirps.id AND lower(users.handle) = lower(sqlc.narg('mention')::text)
+))
+AND (sqlc.narg('mentioned_user_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_mentions
+    WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = sqlc.narg('mentioned_user_id')::uuid
+))
+AND (sqlc.narg('timeline_of')::uuid IS NULL OR user_id = sqlc.narg('timeline_of')::uuid OR user_id IN (
+    SELECT followee_id FROM follows WHERE follower_id = sqlc.narg('timeline_of')::uuid
+))
+AND (
+    sqlc.narg('cursor_time')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_time')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListChirpsByUpdatedAsc :many
+SELECT * FROM chirps
+WHERE NOT hidden AND deleted_at IS NULL
+AND (COALESCE(cardinality(sqlc.arg('author_ids')::uuid[]), 0) = 0 OR user_id = ANY(sqlc.arg('author_ids')::uuid[]))
+AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at > sqlc.narg('created_after')::timestamp)
+AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before')::timestamp)
+AND (sqlc.narg('hashtag')::text IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_hashtags
+    WHERE chirp_hashtags.chirp_id = chirps.id AND chirp_hashtags.tag = lower(sqlc.narg('hashtag')::text)
+))
+AND (sqlc.narg('mention')::text IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_mentions
+    JOIN users ON users.id = chirp_mentions.user_id
+    WHERE chirp_mentions.chirp_id = chirps.id AND lower(users.handle) = lower(sqlc.narg('mention')::text)
+))
+AND (sqlc.narg('mentioned_user_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_mentions
+    WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = sqlc.narg('mentioned_user_id')::uuid
+))
+AND (sqlc.narg('timeline_of')::uuid IS NULL OR user_id = sqlc.narg('timeline_of')::uuid OR user_id IN (
+    SELECT followee_id FROM follows WHERE follower_id = sqlc.narg('timeline_of')::uuid
+))
+AND (
+    sqlc.narg('cursor_time')::timestamp IS NULL
+    OR (updated_at, id) > (sqlc.narg('cursor_time')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY updated_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListChirpsByUpdatedDesc :many
+SELECT * FROM chirps
+WHERE NOT hidden AND deleted_at IS NULL
+AND (COALESCE(cardinality(sqlc.arg('author_ids')::uuid[]), 0) = 0 OR user_id = ANY(sqlc.arg('author_ids')::uuid[]))
+AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at > sqlc.narg('created_after')::timestamp)
+AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before')::timestamp)
+AND (sqlc.narg('hashtag')::text IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_hashtags
+    WHERE chirp_hashtags.chirp_id = chirps.id AND chirp_hashtags.tag = lower(sqlc.narg('hashtag')::text)
+))
+AND (sqlc.narg('mention')::text IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_mentions
+    JOIN users ON users.id = chirp_mentions.user_id
+    WHERE chirp_mentions.chirp_id = chirps.id AND lower(users.handle) = lower(sqlc.narg('mention')::text)
+))
+AND (sqlc.narg('mentioned_user_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM chirp_mentions
+    WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = sqlc.narg('mentioned_user_id')::uuid
+))
+AND (sqlc.narg('timeline_of')::uuid IS NULL OR user_id = sqlc.narg('timeline_of')::uuid OR user_id IN (
+    SELECT followee_id FROM follows WHERE follower_id = sqlc.narg('timeline_of')::uuid
+))
+AND (
+    sqlc.narg('cursor_time')::timestamp IS NULL
+    OR (updated_at, id) < (sqlc.narg('cursor_time')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY updated_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: SetChirpHidden :execrows
+UPDATE chirps
+SET hidden = $2
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetChirpForUpdate :one
+SELECT * FROM chirps
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SearchChirps :many
+SELECT
+    chirps.*,
+    ts_rank_cd(to_tsvector('english', chirps.body), query)::real AS rank,
+    ts_headline('english', translate(chirps.body, chr(2) || chr(3), ''), query, sqlc.arg('headline_options'))::text AS snippet
+FROM chirps, to_tsquery('english', sqlc.arg('query')) query
+WHERE to_tsvector('english', chirps.body) @@ query
+AND NOT chirps.hidden AND chirps.deleted_at IS NULL
+AND (sqlc.narg('author_id')::uuid IS NULL OR chirps.user_id = sqlc.narg('author_id')::uuid)
+AND (sqlc.narg('since')::timestamp IS NULL OR chirps.created_at >= sqlc.narg('since')::timestamp)
+AND (sqlc.narg('until')::timestamp IS NULL OR chirps.created_at < sqlc.narg('until')::timestamp)
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListChirpAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT chirps.*, 1 AS depth
+    FROM chirps
+    WHERE chirps.id = (SELECT c.parent_id FROM chirps c WHERE c.id = sqlc.arg('id'))
+    UNION ALL
+    SELECT chirps.*, ancestors.depth + 1
+    FROM chirps
+    JOIN ancestors ON chirps.id = ancestors.parent_id
+    WHERE ancestors.depth < sqlc.arg('max_depth')::integer
+)
+SELECT * FROM ancestors
+ORDER BY depth DESC;
+
+-- name: ListChirpDescendants :many
+WITH RECURSIVE descendants AS (
+    SELECT chirps.*, 1 AS depth
+    FROM chirps
+    WHERE chirps.parent_id = sqlc.arg('id')::uuid
+    UNION ALL
+    SELECT chirps.*, descendants.depth + 1
+    FROM chirps
+    JOIN descendants ON chirps.parent_id = descendants.id
+    WHERE descendants.depth < sqlc.arg('max_depth')::integer
+)
+SELECT * FROM descendants
+ORDER BY depth, created_at, id
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,29 @@
+-- name: CreateChirpHashtag :exec
+INSERT INTO chirp_hashtags (chirp_id, tag, start_offset, end_offset)
+VALUES ($1, $2, $3, $4);
+
+-- name: CreateChirpMention :exec
+INSERT INTO chirp_mentions (chirp_id, user_id, start_offset, end_offset)
+VALUES ($1, $2, $3, $4);
+
+-- name: DeleteChirpEntities :exec
+WITH deleted_hashtags AS (
+    DELETE FROM chirp_hashtags WHERE chirp_hashtags.chirp_id = $1
+)
+DELETE FROM chirp_mentions WHERE chirp_mentions.chirp_id = $1;
+
+-- name: ListChirpHashtags :many
+SELECT * FROM chirp_hashtags
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_id, start_offset;
+
+-- name: ListChirpMentions :many
+SELECT chirp_mentions.*, users.handle
+FROM chirp_mentions
+JOIN users ON users.id = chirp_mentions.user_id
+WHERE chirp_mentions.chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_mentions.chirp_id, chirp_mentions.start_offset;
+
+-- name: GetUsersByHandles :many
+SELECT * FROM users
+WHERE lower(handle) = ANY(sqlc.arg('handles')::text[]) AND deleted_at IS NULL;
@@ -0,0 +1,67 @@
+-- name: LikeChirp :execrows
+WITH inserted AS (
+    INSERT INTO likes (user_id, chirp_id, created_at)
+    VALUES ($1, $2, NOW())
+    ON CONFLICT DO NOTHING
+    RETURNING chirp_id
+)
+UPDATE chirps
+SET like_count = like_count + 1
+WHERE id IN (SELECT chirp_id FROM inserted);
+
+-- name: UnlikeChirp :execrows
+WITH deleted AS (
+    DELETE FROM likes
+    WHERE likes.user_id = $1 AND likes.chirp_id = $2
+    RETURNING chirp_id
+)
+UPDATE chirps
+SET like_count = like_count - 1
+WHERE id IN (SELECT chirp_id FROM deleted);
+
+-- name: Rechirp :execrows
+WITH inserted AS (
+    INSERT INTO rechirps (user_id, chirp_id, created_at)
+    VALUES ($1, $2, NOW())
+    ON CONFLICT DO NOTHING
+    RETURNING chirp_id
+)
+UPDATE chirps
+SET rechirp_count = rechirp_count + 1
+WHERE id IN (SELECT chirp_id FROM inserted);
+
+-- name: UndoRechirp :execrows
+WITH deleted AS (
+    DELETE FROM rechirps
+    WHERE rechirps.user_id = $1 AND rechirps.chirp_id = $2
+    RETURNING chirp_id
+)
+UPDATE chirps
+SET rechirp_count = rechirp_count - 1
+WHERE id IN (SELECT chirp_id FROM deleted);
+
+-- name: ListLikedChirpIDs :many
+SELECT chirp_id FROM likes
+WHERE user_id = $1 AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: ListRechirpedChirpIDs :many
+SELECT chirp_id FROM rechirps
+WHERE user_id = $1 AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: RecountUserReactions :exec
+UPDATE chirps
+SET like_count = (
+        SELECT count(*) FROM likes
+        JOIN users ON users.id = likes.user_id
+        WHERE likes.chirp_id = chirps.id AND users.deleted_at IS NULL
+    ),
+    rechirp_count = (
+        SELECT count(*) FROM rechirps
+        JOIN users ON users.id = rechirps.user_id
+        WHERE rechirps.chirp_id = chirps.id AND users.deleted_at IS NULL
+    )
+WHERE chirps.id IN (
+    SELECT likes.chirp_id FROM likes WHERE likes.user_id = $1
+    UNION
+    SELECT rechirps.chirp_id FROM rechirps WHERE rechirps.user_id = $1
+);
@@ -0,0 +1,48 @@
+-- name: CreateLoginAttempt :exec
+INSERT INTO login_attempts (id, created_at, email, ip, user_agent, user_id, succeeded)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+);
+
+-- name: RecordLoginFailure :one
+INSERT INTO login_lockouts (key, created_at, updated_at, failures, locked_until)
+VALUES (
+    $1,
+    NOW(),
+    NOW(),
+    1,
+    NULL
+)
+ON CONFLICT (key) DO UPDATE
+SET failures = CASE
+        WHEN login_lockouts.updated_at < NOW() - interval '1 day' THEN 1
+        ELSE login_lockouts.failures + 1
+    END,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: LockLogin :exec
+UPDATE login_lockouts
+SET locked_until = NOW() + sqlc.arg('lock_seconds')::integer * interval '1 second', updated_at = NOW()
+WHERE key = sqlc.arg('key');
+
+-- name: GetActiveLockouts :many
+SELECT * FROM login_lockouts
+WHERE key = ANY(sqlc.arg('keys')::text[])
+AND locked_until > NOW()
+ORDER BY locked_until DESC;
+
+-- name: ListActiveLockouts :many
+SELECT * FROM login_lockouts
+WHERE locked_until > NOW()
+ORDER BY locked_until DESC;
+
+-- name: ClearLockout :execrows
+DELETE FROM login_lockouts
+WHERE key = $1;
@@ -0,0 +1,24 @@
+-- name: ListModerationRules :many
+SELECT * FROM moderation_rules
+ORDER BY term ASC;
+
+-- name: CreateModerationRule :one
+INSERT INTO moderation_rules (id, created_at, updated_at, term, action)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2
+)
+RETURNING *;
+
+-- name: UpdateModerationRule :one
+UPDATE moderation_rules
+SET term = $2, action = $3, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteModerationRule :execrows
+DELETE FROM moderation_rules
+WHERE id = $1;
@@ -0,0 +1,2 @@
+-- name: GetPlan :one
+SELECT * FROM plans WHERE name = $1;
@@ -1,12 +1,17 @@
 -- name: CreateRefreshToken :one
-INSERT INTO refresh_token(token, created_at, updated_at, user_id, expires_at, revoked_at)
+INSERT INTO refresh_token(id, token, created_at, updated_at, user_id, expires_at, revoked_at, family_id, user_agent, ip, last_used_at)
 VALUES(
+    gen_random_uuid(),
     $1,
     NOW(),
     NOW(),
     $2,
     (NOW() + interval '60 day'),
-    NULL
+    NULL,
+    $3,
+    $4,
+    $5,
+    NOW()
 )
 RETURNING *;
 
@@ -16,4 +21,39 @@ SELECT * FROM refresh_token WHERE token = $1;
 -- name: RevokeToken :exec
 UPDATE refresh_token
 SET updated_at = Now(), revoked_at = Now()
-WHERE token = $1;
\ No newline at end of file
+WHERE token = $1;
+
+-- name: RotateRefreshToken :one
+UPDATE refresh_token
+SET updated_at = Now(), revoked_at = Now(), replaced_by = $2
+WHERE token = $1 AND revoked_at IS NULL
+RETURNING *;
+
+-- name: RevokeTokenFamily :exec
+UPDATE refresh_token
+SET updated_at = Now(), revoked_at = Now()
+WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: GetRefreshTokenByID :one
+SELECT * FROM refresh_token
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListActiveSessions :many
+SELECT
+    refresh_token.*,
+    (SELECT MIN(f.created_at) FROM refresh_token f WHERE f.family_id = refresh_token.family_id)::timestamp AS started_at
+FROM refresh_token
+WHERE refresh_token.user_id = $1
+AND refresh_token.revoked_at IS NULL
+AND refresh_token.expires_at > NOW()
+ORDER BY refresh_token.last_used_at DESC;
+
+-- name: RevokeOtherSessions :exec
+UPDATE refresh_token
+SET updated_at = Now(), revoked_at = Now()
+WHERE user_id = $1 AND family_id <> $2 AND revoked_at IS NULL;
+
+-- name: RevokeUserTokens :exec
+UPDATE refresh_token
+SET updated_at = Now(), revoked_at = Now()
+WHERE user_id = $1 AND revoked_at IS NULL;
@@ -0,0 +1,30 @@
+-- name: CreateReport :one
+INSERT INTO reports (id, created_at, updated_at, chirp_id, reporter_id, reason, status)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    'open'
+)
+RETURNING *;
+
+-- name: ListReportQueue :many
+SELECT
+    chirps.*,
+    COUNT(reports.id) AS report_count,
+    array_agg(reports.reason ORDER BY reports.created_at)::text[] AS reasons,
+    MIN(reports.created_at)::timestamp AS first_reported_at
+FROM reports
+JOIN chirps ON chirps.id = reports.chirp_id
+WHERE reports.status = 'open' AND chirps.deleted_at IS NULL
+GROUP BY chirps.id
+ORDER BY report_count DESC, first_reported_at ASC
+LIMIT $1;
+
+-- name: ResolveReports :execrows
+UPDATE reports
+SET status = $2, resolved_by = $3, resolved_at = NOW(), updated_at = NOW()
+WHERE chirp_id = $1 AND status = 'open';
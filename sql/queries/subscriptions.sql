@@ -0,0 +1,59 @@
+-- name: StartSubscription :one
+INSERT INTO subscriptions (user_id, created_at, updated_at, status, started_at, current_period_end)
+VALUES (
+    $1,
+    NOW(),
+    NOW(),
+    'active',
+    NOW(),
+    sqlc.narg('current_period_end')
+)
+ON CONFLICT (user_id) DO UPDATE
+SET updated_at = NOW(),
+    status = 'active',
+    started_at = CASE
+        WHEN subscriptions.status IN ('active', 'canceled') THEN subscriptions.started_at
+        ELSE NOW()
+    END,
+    current_period_end = EXCLUDED.current_period_end,
+    canceled_at = NULL,
+    ended_at = NULL
+RETURNING *;
+
+-- name: CancelSubscription :one
+-- Without a known period end the subscription stays canceled, and keeps its
+-- benefits, until Polka downgrades the user.
+UPDATE subscriptions
+SET updated_at = NOW(),
+    canceled_at = NOW(),
+    current_period_end = COALESCE(sqlc.narg('current_period_end'), current_period_end),
+    status = CASE
+        WHEN COALESCE(sqlc.narg('current_period_end'), current_period_end) <= NOW() THEN 'expired'
+        ELSE 'canceled'
+    END,
+    ended_at = CASE
+        WHEN COALESCE(sqlc.narg('current_period_end'), current_period_end) <= NOW() THEN NOW()
+    END
+WHERE user_id = $1 AND status = 'active'
+RETURNING *;
+
+-- name: EndSubscription :one
+UPDATE subscriptions
+SET updated_at = NOW(), status = $2, ended_at = NOW()
+WHERE user_id = $1 AND status IN ('active', 'canceled')
+RETURNING *;
+
+-- name: GetSubscription :one
+SELECT * FROM subscriptions WHERE user_id = $1;
+
+-- name: ExpireSubscriptions :execrows
+WITH expired AS (
+    UPDATE subscriptions
+    SET updated_at = NOW(), status = 'expired', ended_at = current_period_end
+    WHERE status IN ('active', 'canceled') AND current_period_end <= NOW()
+    RETURNING user_id
+)
+UPDATE users
+SET is_chirpy_red = false, updated_at = NOW()
+FROM expired
+WHERE users.id = expired.user_id;
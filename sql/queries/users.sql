@@ -1,12 +1,14 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password, is_chirpy_red)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, handle)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
     $2,
-    false
+    false,
+    $3,
+    $4
 )
 RETURNING *;
 
@@ -14,16 +16,49 @@ RETURNING *;
 DELETE FROM users;
 
 -- name: GetUserByEmail :one
-SELECT * FROM users WHERE email = $1;
+SELECT * FROM users WHERE email = $1 AND deleted_at IS NULL;
 
 -- name: UpdateUser :one
 UPDATE users
-SET email = $1, hashed_password = $2, updated_at = Now()
-WHERE id = $3
+SET email = $1, hashed_password = $2, handle = COALESCE(sqlc.narg('handle'), handle), updated_at = Now()
+WHERE id = $3 AND deleted_at IS NULL
 RETURNING *;
 
--- name: UpgradeUser :one
+-- name: SetUserChirpyRed :one
 UPDATE users
-SET is_chirpy_red = true
-WHERE id = $1
-RETURNING *;
\ No newline at end of file
+SET is_chirpy_red = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: GetUserByID :one
+SELECT * FROM users WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: SetUserRole :one
+UPDATE users
+SET role = $2, updated_at = Now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: PromoteAdmins :execrows
+UPDATE users
+SET role = 'admin', updated_at = Now()
+WHERE lower(email) = ANY(sqlc.arg('emails')::text[]) AND role <> 'admin' AND deleted_at IS NULL;
+
+-- name: DeleteUser :one
+UPDATE users
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: GetDeletedUserByID :one
+SELECT * FROM users WHERE id = $1 AND deleted_at IS NOT NULL;
+
+-- name: RestoreUser :one
+UPDATE users
+SET deleted_at = NULL, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: PurgeDeletedUsers :execrows
+DELETE FROM users
+WHERE deleted_at < NOW() - sqlc.arg('purge_after_seconds')::integer * interval '1 second';
@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE login_attempts(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    email TEXT NOT NULL,
+    ip TEXT NOT NULL,
+    user_agent TEXT NOT NULL,
+    user_id UUID,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL,
+    succeeded BOOLEAN NOT NULL
+);
+
+CREATE INDEX login_attempts_email_idx ON login_attempts(email, created_at);
+
+CREATE TABLE login_lockouts(
+    key TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    failures INTEGER NOT NULL,
+    locked_until TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE login_lockouts;
+DROP TABLE login_attempts;
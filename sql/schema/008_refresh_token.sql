@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE refresh_token
+ADD id UUID NOT NULL DEFAULT gen_random_uuid(),
+ADD user_agent TEXT NOT NULL DEFAULT '',
+ADD ip TEXT NOT NULL DEFAULT '',
+ADD last_used_at TIMESTAMP NOT NULL DEFAULT NOW(),
+ADD CONSTRAINT refresh_token_id_key UNIQUE (id);
+
+CREATE INDEX refresh_token_user_id_idx ON refresh_token(user_id);
+
+-- +goose Down
+DROP INDEX refresh_token_user_id_idx;
+
+ALTER TABLE refresh_token
+DROP CONSTRAINT refresh_token_id_key,
+DROP COLUMN last_used_at,
+DROP COLUMN ip,
+DROP COLUMN user_agent,
+DROP COLUMN id;
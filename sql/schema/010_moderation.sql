@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE moderation_rules(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    term TEXT NOT NULL UNIQUE,
+    action TEXT NOT NULL CHECK (action IN ('mask', 'flag', 'reject'))
+);
+
+INSERT INTO moderation_rules (id, created_at, updated_at, term, action)
+VALUES
+    (gen_random_uuid(), NOW(), NOW(), 'kerfuffle', 'mask'),
+    (gen_random_uuid(), NOW(), NOW(), 'sharbert', 'mask'),
+    (gen_random_uuid(), NOW(), NOW(), 'fornax', 'mask');
+
+-- +goose Down
+DROP TABLE moderation_rules;
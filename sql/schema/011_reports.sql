@@ -0,0 +1,28 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD hidden BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE reports(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    chirp_id UUID NOT NULL,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+    -- NULL when the moderation pipeline flagged the chirp by itself.
+    reporter_id UUID,
+    FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'dismissed', 'hidden', 'shown', 'deleted')),
+    resolved_by UUID,
+    FOREIGN KEY (resolved_by) REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX reports_open_reporter_idx ON reports(chirp_id, reporter_id) WHERE status = 'open';
+CREATE INDEX reports_status_idx ON reports(status, created_at);
+
+-- +goose Down
+DROP TABLE reports;
+
+ALTER TABLE chirps
+DROP COLUMN hidden;
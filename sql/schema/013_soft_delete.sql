@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE users
+ADD deleted_at TIMESTAMP;
+
+ALTER TABLE chirps
+ADD deleted_at TIMESTAMP,
+ADD deleted_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX users_deleted_at_idx ON users(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX chirps_deleted_at_idx ON chirps(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DELETE FROM chirps WHERE deleted_at IS NOT NULL;
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE chirps
+DROP COLUMN deleted_by,
+DROP COLUMN deleted_at;
+
+ALTER TABLE users
+DROP COLUMN deleted_at;
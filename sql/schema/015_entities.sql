@@ -0,0 +1,38 @@
+-- +goose Up
+ALTER TABLE users
+ADD handle TEXT;
+
+CREATE UNIQUE INDEX users_handle_idx ON users(lower(handle));
+
+-- Offsets count Unicode code points into the chirp body. Chirps written
+-- before this migration have no entities until they are edited.
+CREATE TABLE chirp_hashtags(
+    chirp_id UUID NOT NULL,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    start_offset INTEGER NOT NULL,
+    end_offset INTEGER NOT NULL,
+    PRIMARY KEY (chirp_id, start_offset)
+);
+
+CREATE INDEX chirp_hashtags_tag_idx ON chirp_hashtags(tag);
+
+CREATE TABLE chirp_mentions(
+    chirp_id UUID NOT NULL,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    start_offset INTEGER NOT NULL,
+    end_offset INTEGER NOT NULL,
+    PRIMARY KEY (chirp_id, start_offset)
+);
+
+CREATE INDEX chirp_mentions_user_idx ON chirp_mentions(user_id);
+
+-- +goose Down
+DROP TABLE chirp_mentions;
+DROP TABLE chirp_hashtags;
+DROP INDEX users_handle_idx;
+
+ALTER TABLE users
+DROP COLUMN handle;
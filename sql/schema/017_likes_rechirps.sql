@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE likes(
+    user_id UUID NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    chirp_id UUID NOT NULL,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, chirp_id)
+);
+
+CREATE TABLE rechirps(
+    user_id UUID NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    chirp_id UUID NOT NULL,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, chirp_id)
+);
+
+-- The counts are kept up to date by the queries that add and remove likes
+-- and rechirps, so listing chirps never has to count rows. Reactions of
+-- deleted users are not counted.
+ALTER TABLE chirps
+ADD like_count INTEGER NOT NULL DEFAULT 0,
+ADD rechirp_count INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE chirps
+DROP COLUMN rechirp_count,
+DROP COLUMN like_count;
+
+DROP TABLE rechirps;
+DROP TABLE likes;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- A reply keeps pointing at a soft deleted parent so the thread can show a
+-- placeholder. Once the parent is purged the reply becomes a thread root.
+ALTER TABLE chirps
+ADD parent_id UUID REFERENCES chirps(id) ON DELETE SET NULL;
+
+CREATE INDEX chirps_parent_idx ON chirps(parent_id, created_at) WHERE parent_id IS NOT NULL;
+
+-- +goose Down
+ALTER TABLE chirps
+DROP COLUMN parent_id;
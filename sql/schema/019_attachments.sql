@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE attachments(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    chirp_id UUID NOT NULL,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    blob_key TEXT NOT NULL,
+    thumbnail_content_type TEXT NOT NULL,
+    thumbnail_width INTEGER NOT NULL,
+    thumbnail_height INTEGER NOT NULL,
+    thumbnail_key TEXT NOT NULL
+);
+
+CREATE INDEX attachments_chirp_idx ON attachments(chirp_id, created_at);
+
+-- +goose Down
+DROP TABLE attachments;
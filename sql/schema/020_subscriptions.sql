@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE subscriptions(
+    user_id UUID PRIMARY KEY,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('active', 'canceled', 'expired', 'downgraded', 'refunded')),
+    started_at TIMESTAMP NOT NULL,
+    current_period_end TIMESTAMP,
+    canceled_at TIMESTAMP,
+    ended_at TIMESTAMP
+);
+
+CREATE INDEX subscriptions_period_end_idx ON subscriptions(current_period_end)
+WHERE status IN ('active', 'canceled');
+
+INSERT INTO subscriptions (user_id, created_at, updated_at, status, started_at)
+SELECT id, NOW(), NOW(), 'active', updated_at
+FROM users
+WHERE is_chirpy_red;
+
+-- +goose Down
+DROP TABLE subscriptions;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Limits are relative to the base values in the code and configuration:
+-- the factors multiply per-user rate limits and the maximum upload size.
+CREATE TABLE plans(
+    name TEXT PRIMARY KEY,
+    max_chirp_length INTEGER NOT NULL CHECK (max_chirp_length > 0),
+    edit_chirps BOOLEAN NOT NULL,
+    rate_limit_factor INTEGER NOT NULL CHECK (rate_limit_factor > 0),
+    upload_factor INTEGER NOT NULL CHECK (upload_factor > 0)
+);
+
+INSERT INTO plans (name, max_chirp_length, edit_chirps, rate_limit_factor, upload_factor)
+VALUES
+    ('free', 140, false, 1, 1),
+    ('red', 1000, true, 3, 4);
+
+-- +goose Down
+DROP TABLE plans;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE INDEX chirps_created_at_idx ON chirps(created_at, id);
+CREATE INDEX chirps_user_created_at_idx ON chirps(user_id, created_at, id);
+CREATE INDEX chirps_updated_at_idx ON chirps(updated_at, id);
+CREATE INDEX chirps_user_updated_at_idx ON chirps(user_id, updated_at, id);
+
+-- +goose Down
+DROP INDEX chirps_user_updated_at_idx;
+DROP INDEX chirps_updated_at_idx;
+DROP INDEX chirps_user_created_at_idx;
+DROP INDEX chirps_created_at_idx;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Soft-deleted accounts keep their email, so uniqueness only applies to
+-- live accounts; otherwise the address could never sign up again.
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+CREATE UNIQUE INDEX users_email_idx ON users(email) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX users_email_idx;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	subscriptionActive     = "active"
+	subscriptionCanceled   = "canceled"
+	subscriptionDowngraded = "downgraded"
+	subscriptionRefunded   = "refunded"
+)
+
+// A canceled subscription keeps its benefits until the period ends.
+type Subscription struct {
+	Status           string     `json:"status"`
+	StartedAt        time.Time  `json:"started_at"`
+	CurrentPeriodEnd *time.Time `json:"current_period_end,omitempty"`
+	CanceledAt       *time.Time `json:"canceled_at,omitempty"`
+	EndedAt          *time.Time `json:"ended_at,omitempty"`
+}
+
+func newSubscription(s database.Subscription) *Subscription {
+	return &Subscription{
+		Status:           s.Status,
+		StartedAt:        s.StartedAt,
+		CurrentPeriodEnd: timePtr(s.CurrentPeriodEnd),
+		CanceledAt:       timePtr(s.CanceledAt),
+		EndedAt:          timePtr(s.EndedAt),
+	}
+}
+
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func grantsChirpyRed(status string) bool {
+	return status == subscriptionActive || status == subscriptionCanceled
+}
+
+// userSubscription returns nil for users who never subscribed.
+func (cfg *apiConfig) userSubscription(ctx context.Context, userID uuid.UUID) (*Subscription, error) {
+	sub, err := cfg.db.GetSubscription(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newSubscription(sub), nil
+}
+
+func (cfg *apiConfig) runSubscriptionExpiryJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		expired, err := cfg.db.ExpireSubscriptions(ctx)
+		if err != nil {
+			slog.Error("Couldn't expire subscriptions", "error", err)
+			continue
+		}
+		if expired > 0 {
+			slog.Info("Expired subscriptions", "users", expired)
+		}
+	}
+}
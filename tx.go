@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/RafaelTauschek/http-server/internal/database"
+	"github.com/lib/pq"
+)
+
+// withTx runs fn inside a database transaction, committing if it returns
+// nil and rolling back otherwise.
+func (cfg *apiConfig) withTx(ctx context.Context, fn func(q *database.Queries) error) error {
+	tx, err := cfg.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = fn(database.New(database.Instrument(tx, cfg.metrics.observeQuery)))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// isUniqueViolation reports whether err was caused by a duplicate key in
+// the unique index or constraint called constraint.
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" && pqErr.Constraint == constraint
+}